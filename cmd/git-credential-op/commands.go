@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/helper"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// command is a human-facing subcommand, as opposed to the git credential helper operations.
+type command struct {
+	name  string
+	args  string
+	usage string
+	run   func(h *helper.Helper, args []string) error
+}
+
+var commands = []command{
+	{"list", "", "lists the credentials in the default locations, without secrets", runList},
+	{"status", "[--within <duration>]", "lists the credentials that expire within the duration (the --expiry-warning by default) or have expired", runStatus},
+	{"show", "<url>", "shows the credential git would get for the URL, without the password", runShow},
+	{"add", "<url>", "asks for a username and password and stores them for the URL", runAdd},
+	{"generate", "[--recipe <recipe>] <url>", "stores a new credential for the URL with a password 1Password generates, printing it once", runGenerate},
+	{"rotate", "[--provider <provider>] [--revoke] [--yes] <url>", "replaces the token for the URL with a new one with the same scopes, created through the API of GitLab or Gitea", runRotate},
+	{"remove", "<url>", "archives the item holding the credential for the URL", runRemove},
+	{"test", "<url>", "explains which credential would be used for the URL and why, without asking", runTest},
+	{"import", "[--dry-run] [--file <path>]... [<url>...]", "imports credentials saved by git-credential-store, and those other helpers have for the URLs", runImport},
+	{"export", "[--format store|json] [--redact] [--yes] [--output <file>]", "exports the credentials in the default locations, after confirmation unless redacted", runExport},
+	{"doctor", "", "diagnoses the installation and configuration", runDoctor},
+}
+
+// findCommand returns the subcommand with given name, or nil.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// usage prints the command line usage.
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintf(w, "Usage: git-credential-op [flags] <get|store|erase>\n")
+	fmt.Fprintf(w, "       git-credential-op [flags] <command> [args]\n\nCommands:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", c.name, c.args, c.usage)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// remote parses the single URL argument of a command.
+func remote(args []string) (*helper.Attributes, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected a single URL argument")
+	}
+	attr, err := helper.ParseURL(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if attr.Protocol != "https" {
+		return nil, fmt.Errorf("unsupported protocol %q: only https remotes are supported", attr.Protocol)
+	}
+	return attr, nil
+}
+
+func runList(h *helper.Helper, args []string) error {
+	if len(args) != 0 {
+		return errors.New("unexpected arguments")
+	}
+	creds, err := h.List()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ITEM\tVAULT\tHOST\tPATH")
+	for _, c := range creds {
+		s := h.Summarize(c)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name(), s.Vault, strings.Join(s.Hosts, ","), strings.Join(s.Paths, ","))
+	}
+	return tw.Flush()
+}
+
+func runStatus(h *helper.Helper, args []string) error {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	within := flags.Duration("within", orDefaultDuration(h.ExpiryWarning, helper.DefaultExpiryWarning), "list credentials expiring within the `duration`")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("unexpected arguments")
+	}
+	expiring, err := h.Expiring(*within)
+	if err != nil {
+		return err
+	}
+	if len(expiring) == 0 {
+		fmt.Fprintf(os.Stdout, "No credentials expire within %s\n", *within)
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ITEM\tVAULT\tHOST\tEXPIRES")
+	for _, s := range expiring {
+		state := s.ExpiresAt.Format("2006-01-02")
+		if !s.ExpiresAt.After(time.Now()) {
+			state += " (expired)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name(), s.Vault, strings.Join(s.Hosts, ","), state)
+	}
+	return tw.Flush()
+}
+
+func runShow(h *helper.Helper, args []string) error {
+	attr, err := remote(args)
+	if err != nil {
+		return err
+	}
+	res, err := h.Run(helper.Get, attr)
+	if err != nil {
+		return err
+	}
+	if res == nil || res.Password == "" {
+		return fmt.Errorf("no credential found for %s", attr.RemoteURL())
+	}
+	res.Password = "********"
+	fmt.Fprint(os.Stdout, res)
+	return nil
+}
+
+func runAdd(h *helper.Helper, args []string) error {
+	attr, err := remote(args)
+	if err != nil {
+		return err
+	}
+	res, err := h.Resolve(attr)
+	if err != nil {
+		return err
+	}
+	switch {
+	case res.Reference != nil:
+		return fmt.Errorf("%s is served by the secret reference %s", attr.RemoteURL(), res.Reference)
+	case res.Item != nil:
+		return fmt.Errorf("%s already has a credential in item %q", attr.RemoteURL(), res.Summary().Name())
+	}
+	in := bufio.NewReader(os.Stdin)
+	if attr.Username, err = ask(in, "Username", attr.Username); err != nil {
+		return err
+	}
+	if attr.Password, err = askSecret(in, "Password"); err != nil {
+		return err
+	}
+	if attr.Username == "" || attr.Password == "" {
+		return errors.New("username and password are required")
+	}
+	if _, err := h.Run(helper.Store, attr); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Stored credential for %s\n", attr.RemoteURL())
+	return nil
+}
+
+func runGenerate(h *helper.Helper, args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	recipe := flags.String("recipe", "", "the `recipe` for the password, e.g. letters,digits,32; overrides the configuration")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	attr, err := remote(flags.Args())
+	if err != nil {
+		return err
+	}
+	if *recipe != "" {
+		r, err := opcli.ParseRecipe(*recipe)
+		if err != nil {
+			return err
+		}
+		// an explicit recipe applies regardless of the rules
+		h.Recipe = r
+		for i := range h.Rules {
+			h.Rules[i].Recipe = opcli.Recipe{}
+		}
+	}
+	if attr.Username == "" {
+		if attr.Username, err = ask(bufio.NewReader(os.Stdin), "Username", ""); err != nil {
+			return err
+		}
+	}
+	res, item, err := h.Generate(attr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Stored credential for %s in item %q in vault %s\n", attr.RemoteURL(), item.Title, item.Vault.Name)
+	fmt.Fprintf(os.Stdout, "username=%s\npassword=%s\n", res.Username, res.Password)
+	return nil
+}
+
+func runRemove(h *helper.Helper, args []string) error {
+	attr, err := remote(args)
+	if err != nil {
+		return err
+	}
+	res, err := h.Resolve(attr)
+	if err != nil {
+		return err
+	}
+	if res.Item == nil && res.Reference == nil {
+		return fmt.Errorf("no credential found for %s", attr.RemoteURL())
+	}
+	if res.Item != nil {
+		s := res.Summary()
+		answer, err := ask(bufio.NewReader(os.Stdin), fmt.Sprintf("Archive item %q in vault %s? [y/N]", s.Title, s.Vault), "")
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return helper.ErrCanceled
+		}
+	}
+	if err := res.Remove(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Archived credential for %s\n", attr.RemoteURL())
+	return nil
+}
+
+func runTest(h *helper.Helper, args []string) error {
+	attr, err := remote(args)
+	if err != nil {
+		return err
+	}
+	// never ask; report what happens when there's no terminal instead
+	h.Chooser = nil
+	res, err := h.Resolve(attr)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+	s := res.Settings
+	fmt.Fprintf(tw, "Remote:\t%s\n", attr.RemoteURL())
+	if res.Reference != nil {
+		fmt.Fprintf(tw, "Source:\tsecret reference %s\n", res.Reference)
+		return nil
+	}
+	fmt.Fprintf(tw, "Account:\t%s\n", orDefault(s.Account, "(signed in account)"))
+	fmt.Fprintf(tw, "Locations:\t%s\n", locationsOf(s))
+	fmt.Fprintf(tw, "Categories:\t%s\n", categoriesOf(s))
+	if s.Tag != "" {
+		fmt.Fprintf(tw, "Tag:\t%s\n", s.Tag)
+	}
+	if s.Item != "" {
+		fmt.Fprintf(tw, "Item:\t%s\n", s.Item)
+	}
+	if len(res.Matches) == 0 {
+		fmt.Fprintf(tw, "Result:\tno credential found\n")
+		return nil
+	}
+	fmt.Fprintf(tw, "Found in:\t%s\n", orDefault(res.Location.String(), "(default vault)"))
+	for i, m := range res.Matches {
+		ms := h.Summarize(m)
+		mark := ""
+		if m == res.Item {
+			mark = " (used)"
+		}
+		fmt.Fprintf(tw, "Match %d:\t%s in %s, hosts %s, paths %s%s\n", i+1, ms.Name(), ms.Vault,
+			orDefault(strings.Join(ms.Hosts, ","), "-"), orDefault(strings.Join(ms.Paths, ","), "any"), mark)
+	}
+	if res.Item == nil {
+		fmt.Fprintf(tw, "Result:\tnone used (%s)\n", res.Reason)
+	} else {
+		fmt.Fprintf(tw, "Result:\t%s, username %s (%s)\n", res.Summary().Name(), orDefault(res.Summary().Username, "-"), res.Reason)
+	}
+	return nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func orDefaultDuration(d, def time.Duration) time.Duration {
+	if d == 0 {
+		return def
+	}
+	return d
+}
+
+func locationsOf(s helper.Settings) string {
+	if len(s.Locations) == 0 {
+		return orDefault(s.Vault, "(default vault)")
+	}
+	var v []string
+	for _, l := range s.Locations {
+		v = append(v, l.String())
+	}
+	return strings.Join(v, ", ")
+}
+
+func categoriesOf(s helper.Settings) string {
+	if len(s.Categories) == 0 {
+		return opcli.CategoryAPICredential
+	}
+	var v []string
+	for _, c := range s.Categories {
+		v = append(v, string(c))
+	}
+	return strings.Join(v, ", ")
+}
+
+// ask prompts for a line of input, returning the default value when the input is empty.
+func ask(in *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", helper.ErrCanceled
+	}
+	return orDefault(strings.TrimSpace(line), def), nil
+}
+
+// askSecret prompts for a line of input without echoing it when reading from a terminal.
+func askSecret(in *bufio.Reader, label string) (string, error) {
+	if fi, err := os.Stdin.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		if err := stty("-echo"); err == nil {
+			defer func() {
+				_ = stty("echo")
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", helper.ErrCanceled
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/helper"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// dockerName is the name the binary is installed under (e.g. as a symlink) to act as a Docker credential helper.
+const dockerName = "docker-credential-op"
+
+// isDocker checks if the binary is invoked as a Docker credential helper.
+func isDocker() bool {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") == dockerName
+}
+
+// runDocker runs the Docker credential helper action and returns the exit code. Unlike git, Docker reads errors from stdout.
+func runDocker(h *helper.Helper, action string) int {
+	if action == "version" {
+		fmt.Fprintf(os.Stdout, "%s (git-credential-op) %s\n", dockerName, version())
+		return 0
+	}
+	// git configuration doesn't apply to registries
+	h.GitConfig = false
+	err := detectOp(h)
+	if !errors.Is(err, opcli.ErrUnsupportedVersion) {
+		err = h.ServeDocker(action, os.Stdin, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stdout, err)
+		return 1
+	}
+	return 0
+}
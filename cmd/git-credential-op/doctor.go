@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/helper"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+type status int
+
+const (
+	statusOK status = iota
+	statusWarn
+	statusFail
+)
+
+func (s status) String() string {
+	switch s {
+	case statusWarn:
+		return "warn"
+	case statusFail:
+		return "FAIL"
+	default:
+		return " ok "
+	}
+}
+
+// finding is the result of a single diagnostic check with a hint on how to fix it.
+type finding struct {
+	status  status
+	message string
+	hint    string
+}
+
+// doctor runs the diagnostic checks, skipping those depending on a failed one.
+type doctor struct {
+	h        *helper.Helper
+	findings []finding
+}
+
+func (d *doctor) report(s status, message, hint string) {
+	d.findings = append(d.findings, finding{s, message, hint})
+}
+
+func runDoctor(h *helper.Helper, args []string) error {
+	if len(args) != 0 {
+		return errors.New("unexpected arguments")
+	}
+	d := &doctor{h: h}
+	if d.checkOp() && d.checkSignIn() {
+		d.checkVaults()
+	}
+	d.checkGitHelpers()
+
+	failed := 0
+	for _, f := range d.findings {
+		fmt.Fprintf(os.Stdout, "[%s] %s\n", f.status, f.message)
+		if f.hint != "" && f.status != statusOK {
+			fmt.Fprintf(os.Stdout, "       %s\n", f.hint)
+		}
+		if f.status == statusFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkOp checks that op is found on $PATH and is recent enough.
+func (d *doctor) checkOp() bool {
+	path := d.h.Op.Path
+	if path == "" {
+		p, err := exec.LookPath("op")
+		if err != nil {
+			d.report(statusFail, "1Password CLI (op) not found on $PATH",
+				"Install it from https://developer.1password.com/docs/cli/get-started/ or set its location with $PATH.")
+			return false
+		}
+		path = p
+		d.checkPath(p)
+	}
+	err := d.h.Op.Detect()
+	switch {
+	case errors.Is(err, opcli.ErrUnsupportedVersion):
+		d.report(statusFail, fmt.Sprintf("%s version %s is not supported", path, d.h.Op.Installed),
+			fmt.Sprintf("Update 1Password CLI to version %s or later.", opcli.MinVersion))
+		return false
+	case err != nil:
+		d.report(statusFail, fmt.Sprintf("unable to run %s: %v", path, err), "Reinstall 1Password CLI.")
+		return false
+	}
+	d.report(statusOK, fmt.Sprintf("%s version %s", path, d.h.Op.Installed), "")
+	return true
+}
+
+// checkPath reports when adding the default install location to $PATH changes which op is used.
+func (d *doctor) checkPath(path string) {
+	original, err := lookPathIn("op", originalPath)
+	switch {
+	case err != nil:
+		d.report(statusOK, fmt.Sprintf("op is only found in %s, which the helper adds to $PATH", opInstallDir), "")
+	case original != path:
+		d.report(statusWarn, fmt.Sprintf("the helper uses %s instead of %s found first on $PATH", path, original),
+			fmt.Sprintf("The helper always searches %s first; remove the outdated copy of op from there.", opInstallDir))
+	}
+}
+
+// checkSignIn checks that op has accounts and is signed in, preferably through the 1Password app integration.
+func (d *doctor) checkSignIn() bool {
+	accounts, err := d.h.Op.ListAccounts()
+	if err != nil {
+		d.report(statusFail, fmt.Sprintf("unable to list accounts: %v", err), "")
+		return false
+	}
+	if len(accounts) == 0 && os.Getenv("OP_SERVICE_ACCOUNT_TOKEN") == "" {
+		d.report(statusFail, "no 1Password accounts added to op",
+			"Turn on \"Integrate with 1Password CLI\" in the 1Password app under Settings > Developer, or run `op account add`.")
+		return false
+	}
+	var urls []string
+	for _, a := range accounts {
+		urls = append(urls, a.URL)
+	}
+	d.report(statusOK, fmt.Sprintf("accounts: %s", orDefault(strings.Join(urls, ", "), "(service account)")), "")
+
+	me, err := d.h.Op.WhoAmI()
+	if err != nil {
+		d.report(statusFail, fmt.Sprintf("op is not signed in%s: %v", accountSuffix(d.h.Op.Account), err),
+			"Unlock the 1Password app with the CLI integration turned on, or sign in with `eval $(op signin)`.")
+		return false
+	}
+	d.report(statusOK, fmt.Sprintf("signed in to %s as %s", me.URL, orDefault(me.Email, me.UserType)), "")
+
+	switch {
+	case os.Getenv("OP_SERVICE_ACCOUNT_TOKEN") != "":
+		d.report(statusOK, "authenticated with a service account token", "")
+	case hasSessionToken():
+		d.report(statusWarn, "authenticated with a manual session token, which expires after 30 minutes of inactivity",
+			"Turn on \"Integrate with 1Password CLI\" in the 1Password app under Settings > Developer to unlock op with the app.")
+	default:
+		d.report(statusOK, "authenticated through the 1Password app integration", "")
+	}
+	return true
+}
+
+// checkVaults checks that the configured vaults are accessible.
+func (d *doctor) checkVaults() {
+	locations := d.h.Locations
+	if len(locations) == 0 {
+		locations = []helper.Location{{Account: d.h.Op.Account, Vault: d.h.Vault}}
+	}
+	for _, l := range locations {
+		if l.Vault == "" {
+			d.report(statusOK, fmt.Sprintf("searching all vaults%s", accountSuffix(l.Account)), "")
+			continue
+		}
+		op := d.h.Op
+		if l.Account != "" {
+			op.Account = l.Account
+		}
+		if _, err := op.GetVault(l.Vault); err != nil {
+			d.report(statusFail, fmt.Sprintf("vault %q is not accessible%s: %v", l.Vault, accountSuffix(l.Account), err),
+				"Check the vault name and your permissions with `op vault list`.")
+			continue
+		}
+		d.report(statusOK, fmt.Sprintf("vault %q is accessible%s", l.Vault, accountSuffix(l.Account)), "")
+	}
+}
+
+// checkGitHelpers checks that the helper is configured in git and not shadowed by other helpers.
+func (d *doctor) checkGitHelpers() {
+	helpers, err := gitHelpers()
+	if err != nil {
+		d.report(statusFail, fmt.Sprintf("unable to read git configuration: %v", err), "")
+		return
+	}
+	ours := -1
+	for i, v := range helpers {
+		if isOurHelper(v) {
+			ours = i
+			break
+		}
+	}
+	if ours < 0 {
+		d.report(statusFail, "git is not configured to use the helper",
+			"Run `git config --global credential.helper op`.")
+		return
+	}
+	d.report(statusOK, fmt.Sprintf("git uses the helper %q", helpers[ours]), "")
+	for i, v := range helpers {
+		switch {
+		case i < ours:
+			d.report(statusWarn, fmt.Sprintf("git asks the helper %q first, so it may answer instead of 1Password", v),
+				fmt.Sprintf("Remove it with `git config --global --unset credential.helper %q`, or add an empty helper before ours to reset the list.", v))
+		case i > ours && strings.HasPrefix(v, "store"):
+			d.report(statusWarn, fmt.Sprintf("the helper %q saves plaintext copies of the credentials from 1Password", v),
+				"Remove it, since git passes credentials to all configured helpers to store.")
+		case i > ours && !isOurHelper(v):
+			d.report(statusWarn, fmt.Sprintf("the helper %q also stores the credentials from 1Password", v),
+				"Remove it unless keeping a copy of the credentials there is intended.")
+		}
+	}
+}
+
+// gitHelpers returns the credential helpers configured in git, in the order git asks them.
+func gitHelpers() ([]string, error) {
+	out, err := exec.Command("git", "config", "--get-all", "credential.helper").Output()
+	var ee *exec.ExitError
+	if err != nil && !(errors.As(err, &ee) && ee.ExitCode() == 1) {
+		return nil, err
+	}
+	var helpers []string
+	for _, v := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if v = strings.TrimSpace(v); v == "" {
+			// an empty value resets the list of helpers
+			helpers = nil
+			continue
+		}
+		helpers = append(helpers, v)
+	}
+	return helpers, nil
+}
+
+// isOurHelper checks if the credential.helper value refers to this helper.
+func isOurHelper(v string) bool {
+	name := strings.Fields(v)[0]
+	return name == "op" || strings.TrimPrefix(filepath.Base(name), "!") == "git-credential-op"
+}
+
+func hasSessionToken() bool {
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, "OP_SESSION_") {
+			return true
+		}
+	}
+	return false
+}
+
+func accountSuffix(account string) string {
+	if account == "" {
+		return ""
+	}
+	return " in account " + account
+}
+
+// lookPathIn searches for the executable in the directories of the given $PATH value.
+func lookPathIn(file, path string) (string, error) {
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		p := filepath.Join(dir, file)
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() && fi.Mode()&0111 != 0 {
+			return p, nil
+		}
+	}
+	return "", exec.ErrNotFound
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHelpers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		expect []string
+	}{
+		{
+			name: "None",
+		},
+		{
+			name:   "Single",
+			config: "[credential]\n\thelper = op\n",
+			expect: []string{"op"},
+		},
+		{
+			name:   "Ordered",
+			config: "[credential]\n\thelper = cache --timeout 300\n\thelper = /usr/local/bin/git-credential-op --vault Work\n",
+			expect: []string{"cache --timeout 300", "/usr/local/bin/git-credential-op --vault Work"},
+		},
+		{
+			name:   "Reset",
+			config: "[credential]\n\thelper = osxkeychain\n\thelper =\n\thelper = op\n",
+			expect: []string{"op"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockGitConfig(t, test.config)
+			helpers, err := gitHelpers()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, helpers)
+		})
+	}
+}
+
+func TestIsOurHelper(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect bool
+	}{
+		{value: "op", expect: true},
+		{value: "op --vault Work", expect: true},
+		{value: "/usr/local/bin/git-credential-op", expect: true},
+		{value: "!git-credential-op --exclusive", expect: true},
+		{value: "store"},
+		{value: "osxkeychain"},
+		{value: "/usr/local/bin/git-credential-manager"},
+		{value: "!f() { echo password=foo; }; f"},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			assert.Equal(t, test.expect, isOurHelper(test.value))
+		})
+	}
+}
+
+func TestCheckGitHelpers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		expect []finding
+	}{
+		{
+			name: "NotConfigured",
+			expect: []finding{
+				{statusFail, "git is not configured to use the helper", "Run `git config --global credential.helper op`."},
+			},
+		},
+		{
+			name:   "Configured",
+			config: "[credential]\n\thelper = op\n",
+			expect: []finding{
+				{statusOK, `git uses the helper "op"`, ""},
+			},
+		},
+		{
+			name:   "Shadowed",
+			config: "[credential]\n\thelper = osxkeychain\n\thelper = op\n",
+			expect: []finding{
+				{statusOK, `git uses the helper "op"`, ""},
+				{statusWarn, `git asks the helper "osxkeychain" first, so it may answer instead of 1Password`,
+					"Remove it with `git config --global --unset credential.helper \"osxkeychain\"`, or add an empty helper before ours to reset the list."},
+			},
+		},
+		{
+			name:   "Copies",
+			config: "[credential]\n\thelper = op\n\thelper = store\n\thelper = cache\n",
+			expect: []finding{
+				{statusOK, `git uses the helper "op"`, ""},
+				{statusWarn, `the helper "store" saves plaintext copies of the credentials from 1Password`,
+					"Remove it, since git passes credentials to all configured helpers to store."},
+				{statusWarn, `the helper "cache" also stores the credentials from 1Password`,
+					"Remove it unless keeping a copy of the credentials there is intended."},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockGitConfig(t, test.config)
+			d := &doctor{}
+			d.checkGitHelpers()
+			assert.Equal(t, test.expect, d.findings)
+		})
+	}
+}
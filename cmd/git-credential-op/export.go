@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/helper"
+)
+
+// exportDocument is the JSON export format.
+type exportDocument struct {
+	ExportedAt  time.Time          `json:"exported_at"`
+	Redacted    bool               `json:"redacted"`
+	Credentials []exportCredential `json:"credentials"`
+}
+
+type exportCredential struct {
+	ID        string    `json:"id"`
+	Item      string    `json:"item"`
+	Section   string    `json:"section,omitempty"`
+	Vault     string    `json:"vault"`
+	URL       string    `json:"url"`
+	Host      string    `json:"host"`
+	Path      string    `json:"path,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Password  string    `json:"password,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func runExport(h *helper.Helper, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "store", "output `format`: store for git-credential-store lines, or json")
+	output := flags.String("output", "", "write to a new `file` instead of stdout")
+	redact := flags.Bool("redact", false, "leave out the passwords, e.g. for auditing")
+	yes := flags.Bool("yes", false, "don't ask for confirmation before writing passwords")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("unexpected arguments")
+	}
+	if *format != "store" && *format != "json" {
+		return fmt.Errorf("unsupported format %q: expected store or json", *format)
+	}
+
+	exported, err := h.Export()
+	if err != nil {
+		return err
+	}
+	if *redact {
+		for _, e := range exported {
+			e.Credential.Password = ""
+		}
+	} else if !*yes {
+		answer, err := ask(bufio.NewReader(os.Stdin), fmt.Sprintf("Write %d passwords in plaintext to %s? [y/N]", len(exported), orDefault(*output, "stdout")), "")
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return helper.ErrCanceled
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		// never overwrite an existing file, nor let others read the secrets
+		f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "json" {
+		err = writeJSON(w, exported, *redact)
+	} else {
+		err = writeStore(w, exported)
+	}
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d credentials to %s\n", len(exported), *output)
+	}
+	return nil
+}
+
+// writeStore writes the credentials in the git-credential-store format.
+// See https://git-scm.com/docs/git-credential-store#_storage_format.
+func writeStore(w io.Writer, exported []helper.Exported) error {
+	for _, e := range exported {
+		c := e.Credential
+		u := url.URL{Scheme: c.Protocol, Host: c.Host}
+		if c.Path != "" {
+			u.Path = "/" + c.Path
+		}
+		if c.Password != "" {
+			u.User = url.UserPassword(c.Username, c.Password)
+		} else if c.Username != "" {
+			u.User = url.User(c.Username)
+		}
+		if _, err := fmt.Fprintln(w, u.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeJSON(w io.Writer, exported []helper.Exported, redacted bool) error {
+	doc := exportDocument{
+		ExportedAt:  time.Now().UTC(),
+		Redacted:    redacted,
+		Credentials: []exportCredential{},
+	}
+	for _, e := range exported {
+		s, c := e.Summary, e.Credential
+		doc.Credentials = append(doc.Credentials, exportCredential{
+			ID:        s.ID,
+			Item:      s.Title,
+			Section:   s.Section,
+			Vault:     s.Vault,
+			URL:       c.RemoteURL(),
+			Host:      c.Host,
+			Path:      c.Path,
+			Username:  c.Username,
+			Password:  c.Password,
+			UpdatedAt: s.UpdatedAt,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
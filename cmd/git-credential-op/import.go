@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gbernady/git-credential-op/pkg/helper"
+)
+
+func runImport(h *helper.Helper, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without creating any items")
+	var files []string
+	flags.Func("file", "git-credential-store `file` to import (repeatable; default ~/.git-credentials and ~/.config/git/credentials)", func(s string) error {
+		files = append(files, s)
+		return nil
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var creds []*helper.Attributes
+	var sources []string
+	explicit := len(files) > 0
+	if !explicit {
+		files = defaultStoreFiles()
+	}
+	for _, path := range files {
+		c, err := readStoreFile(path)
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			continue
+		} else if err != nil {
+			return err
+		}
+		creds = append(creds, c...)
+		for range c {
+			sources = append(sources, path)
+		}
+	}
+	if flags.NArg() > 0 {
+		helpers, err := gitHelpers()
+		if err != nil {
+			return fmt.Errorf("unable to read git configuration: %w", err)
+		}
+		for _, u := range flags.Args() {
+			for _, name := range helpers {
+				if isOurHelper(name) {
+					continue
+				}
+				c, err := fill(name, u)
+				if err != nil {
+					return err
+				}
+				if c != nil {
+					creds = append(creds, c)
+					sources = append(sources, "helper "+name)
+				}
+			}
+		}
+	}
+	if len(creds) == 0 {
+		return errors.New("no credentials found to import")
+	}
+
+	results, err := h.Import(creds, *dryRun)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tURL\tUSERNAME\tRESULT")
+	imported := 0
+	for i, r := range results {
+		result := "skipped: " + r.Skipped
+		if r.Skipped == "" {
+			imported++
+			result = "imported to " + orDefault(r.Location.String(), "(default vault)")
+			if *dryRun {
+				result = "would import to " + orDefault(r.Location.String(), "(default vault)")
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", sources[i], r.Credential.RemoteURL(), r.Credential.Username, result)
+	}
+	tw.Flush()
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Fprintf(os.Stdout, "\n%d of %d credentials would be imported; run without --dry-run to import them\n", imported, len(results))
+	} else {
+		fmt.Fprintf(os.Stdout, "\n%d of %d credentials imported\n", imported, len(results))
+	}
+	return nil
+}
+
+// defaultStoreFiles returns the files git-credential-store reads by default.
+// See https://git-scm.com/docs/git-credential-store#FILES.
+func defaultStoreFiles() []string {
+	var files []string
+	home, err := os.UserHomeDir()
+	if err == nil {
+		files = append(files, filepath.Join(home, ".git-credentials"))
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		files = append(files, filepath.Join(dir, "git", "credentials"))
+	} else if err == nil {
+		files = append(files, filepath.Join(home, ".config", "git", "credentials"))
+	}
+	return files
+}
+
+func readStoreFile(path string) ([]*helper.Attributes, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	creds, err := helper.ParseCredentialStore(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// fill asks another credential helper for the credential for the URL through git, without ever prompting.
+// It returns nil when the helper has no credential for the URL.
+func fill(name, u string) (*helper.Attributes, error) {
+	cmd := exec.Command("git", "-c", "credential.helper=", "-c", "credential.helper="+name, "credential", "fill")
+	// git prompts when no helper answers; make it fail instead
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=")
+	cmd.Stdin = strings.NewReader("url=" + u + "\n\n")
+	out, err := cmd.Output()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	attr, err := helper.ParseAttributes(strings.NewReader(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("git credential fill: %w", err)
+	}
+	if attr.Username == "" || attr.Password == "" {
+		return nil, nil
+	}
+	return attr, nil
+}
@@ -1,28 +1,102 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"runtime/debug"
+	"slices"
+	"strings"
 
 	"github.com/gbernady/git-credential-op/pkg/helper"
 	"github.com/gbernady/git-credential-op/pkg/opcli"
 )
 
 var (
-	accountFlag = flag.String("account", "", "the account to use (if more than one is available)")
-	vaultFlag   = flag.String("vault", "", "the vault to use; defaults to the Personal vault")
-	versionFlag = flag.Bool("version", false, "prints helper and 1Password CLI versions")
+	accountFlag   = flag.String("account", "", "the account to use (if more than one is available)")
+	cacheFlag     = flag.Duration("cache-ttl", 0, "how long to cache item metadata (never secrets) between runs; disabled by default")
+	configFlag    = flag.String("config", "", "the configuration file to use; defaults to $XDG_CONFIG_HOME/git-credential-op/config.yaml")
+	debugFlag     = flag.Bool("debug", false, "traces op commands and matching decisions to stderr (see also GIT_CREDENTIAL_OP_TRACE)")
+	exclusiveFlag = flag.Bool("exclusive", false, "stops git from asking other helpers and prompting for the credential when none is found")
+	expiryFlag    = flag.Duration("expiry-warning", helper.DefaultExpiryWarning, "warns on stderr when the credential used expires within the duration; 0 disables")
+	fallbackFlag  = flag.String("fallback", "", "selects an item when several match and there's no terminal to ask: first (default), newest, or none")
+	itemFlag      = flag.String("item", "", "the item (name, ID, or sharing link) to use, skipping the search")
+	tagFlag       = flag.String("tag", "", "only consider items with the tag (e.g. git-credential)")
+	vaultFlag     = flag.String("vault", "", "the vault to use; defaults to the Personal vault")
+	validateFlag  = flag.Bool("validate", false, "checks credentials against the API of GitHub, GitLab, or Gitea before returning them, trying the next match when one is rejected")
+	versionFlag   = flag.Bool("version", false, "prints helper and 1Password CLI versions")
+
+	locationFlags  locationsFlag
+	referenceFlags referencesFlag
+
+	// originalPath is $PATH before adding the default install location of 1Password CLI.
+	originalPath = os.Getenv("PATH")
 )
 
+// locationsFlag collects search locations given with the repeatable --location flag.
+type locationsFlag []helper.Location
+
+func (f *locationsFlag) String() string {
+	var s []string
+	for _, l := range *f {
+		s = append(s, l.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func (f *locationsFlag) Set(value string) error {
+	l, err := helper.ParseLocation(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, l)
+	return nil
+}
+
+// referencesFlag collects secret references given with the repeatable --reference flag.
+type referencesFlag []helper.Reference
+
+func (f *referencesFlag) String() string {
+	var s []string
+	for _, r := range *f {
+		s = append(s, r.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func (f *referencesFlag) Set(value string) error {
+	r, err := helper.ParseReference(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, r)
+	return nil
+}
+
+// opInstallDir is the default install location for 1Password CLI.
+const opInstallDir = "/usr/local/bin"
+
 func init() {
+	flag.Usage = usage
+	flag.Var(&locationFlags, "location", "searches the vault as [account:]vault; repeat to search several locations in order, storing in the first one")
+	flag.Var(&referenceFlags, "reference", "maps a remote to secret references as host[/path]=[username,]password (repeatable)")
+
 	// Ensure default install location for 1Password CLI is on $PATH.
 	// Reference: https://developer.1password.com/docs/cli/get-started/
-	os.Setenv("PATH", fmt.Sprintf("/usr/local/bin:%s", os.Getenv("PATH")))
+	os.Setenv("PATH", fmt.Sprintf("%s:%s", opInstallDir, originalPath))
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// run runs the helper and returns the exit code, so that deferred cleanup happens before exiting.
+func run() int {
 	flag.Parse()
 
 	op := opcli.CLI{
@@ -37,19 +111,175 @@ func main() {
 		} else {
 			fmt.Fprintf(os.Stdout, "op version %s\n", v)
 		}
-		return
+		return 0
 	}
 
-	h := &helper.Helper{
-		Op:    op,
-		Vault: *vaultFlag,
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	h, err := newHelper(set)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
 	}
-	res, err := h.Run(helper.Operation(flag.Arg(0)), helper.ParseAttributes(os.Stdin))
+	logger, closeTrace, err := newLogger()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
-	} else {
-		fmt.Fprintln(os.Stdout, res)
+		return 1
+	}
+	defer closeTrace()
+	h.Logger, h.Op.Logger = logger, logger
+	if isDocker() {
+		return runDocker(h, flag.Arg(0))
+	}
+	c := findCommand(flag.Arg(0))
+	// git runs the helper for every request, so git operations reuse the cached op version;
+	// other failures to run op are reported by the operation itself, and doctor explains them all
+	detect := h.Op.Detect
+	if c == nil {
+		detect = func() error { return detectOp(h) }
+	}
+	if err := detect(); errors.Is(err, opcli.ErrUnsupportedVersion) && (c == nil || c.name != "doctor") {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if c != nil {
+		if err := c.run(h, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "git-credential-op %s: %v\n", c.name, err)
+			return 1
+		}
+		return 0
+	}
+	if err := h.Serve(helper.Operation(flag.Arg(0)), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return 0
+}
+
+// detectOp detects the op version, caching it next to the metadata cache file.
+func detectOp(h *helper.Helper) error {
+	path, err := helper.DefaultCachePath()
+	if h.Cache != nil {
+		path, err = h.Cache.Path, nil
+	}
+	if err != nil {
+		return h.Op.Detect()
+	}
+	return helper.DetectOp(&h.Op, filepath.Join(filepath.Dir(path), "op.json"))
+}
+
+// newHelper configures the helper from the configuration file and the flags set on the command line,
+// which take precedence.
+func newHelper(set map[string]bool) (*helper.Helper, error) {
+	h := &helper.Helper{
+		Chooser:       helper.TerminalChooser,
+		GitConfig:     true,
+		ExpiryWarning: helper.DefaultExpiryWarning,
+		Warnings:      os.Stderr,
+	}
+
+	path := *configFlag
+	explicit := path != "" || os.Getenv("GIT_CREDENTIAL_OP_CONFIG") != ""
+	if path == "" {
+		p, err := helper.DefaultConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+	c, err := helper.LoadConfig(path)
+	if err == nil {
+		if err := c.Apply(h); err != nil {
+			return nil, err
+		}
+	} else if explicit || !errors.Is(err, fs.ErrNotExist) {
+		// the default configuration file is optional
+		return nil, err
+	}
+
+	// flags take precedence over the configuration file
+	if set["account"] {
+		h.Op.Account = *accountFlag
+	}
+	if set["vault"] {
+		h.Vault = *vaultFlag
+	}
+	if set["fallback"] {
+		f, err := helper.ParseFallback(*fallbackFlag)
+		if err != nil {
+			return nil, err
+		}
+		h.Fallback = f
+	}
+	if set["item"] {
+		h.Item = *itemFlag
+	}
+	if set["tag"] {
+		h.Tag = *tagFlag
+	}
+	if set["location"] {
+		h.Locations = locationFlags
+	} else if set["account"] || set["vault"] {
+		// an explicit account or vault narrows the search down to it
+		h.Locations = nil
+	}
+	if set["validate"] {
+		switch {
+		case !*validateFlag:
+			h.Validator = nil
+		case h.Validator == nil:
+			path, err := helper.DefaultValidationPath()
+			if err != nil {
+				return nil, fmt.Errorf("unable to locate cache directory: %w", err)
+			}
+			h.Validator = &helper.Validator{Path: path, TTL: helper.DefaultValidationTTL}
+		}
+	}
+	if set["exclusive"] {
+		h.Exclusive = *exclusiveFlag
+	}
+	if set["expiry-warning"] {
+		h.ExpiryWarning = *expiryFlag
+	}
+	if set["cache-ttl"] {
+		switch {
+		case *cacheFlag <= 0:
+			h.Cache = nil
+		case h.Cache != nil:
+			h.Cache.TTL = *cacheFlag
+		default:
+			path, err := helper.DefaultCachePath()
+			if err != nil {
+				return nil, fmt.Errorf("unable to locate cache directory: %w", err)
+			}
+			h.Cache = &helper.Cache{Path: path, TTL: *cacheFlag}
+		}
+	}
+	h.References = append(slices.Clone(referenceFlags), h.References...)
+	return h, nil
+}
+
+// newLogger returns the logger tracing the helper, or nil when tracing is disabled.
+//
+// Like GIT_TRACE, GIT_CREDENTIAL_OP_TRACE enables tracing to stderr when set to 1, 2, or true,
+// and appends to the file when set to an absolute path. The --debug flag always traces to stderr.
+func newLogger() (*slog.Logger, func(), error) {
+	w := io.Writer(nil)
+	closer := func() {}
+	switch v := os.Getenv("GIT_CREDENTIAL_OP_TRACE"); {
+	case *debugFlag || v == "1" || v == "2" || strings.EqualFold(v, "true"):
+		w = os.Stderr
+	case filepath.IsAbs(v):
+		f, err := os.OpenFile(v, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to open trace file: %w", err)
+		}
+		w, closer = f, func() { f.Close() }
+	}
+	if w == nil {
+		return nil, closer, nil
 	}
+	h := slog.NewTextHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(h).With("pid", os.Getpid()), closer, nil
 }
 
 func version() string {
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/helper"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockGitConfig points git to a global configuration file with the given content.
+func mockGitConfig(t *testing.T, config string) {
+	path := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Error(err)
+	}
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", path)
+}
+
+// mockConfig points the helper to a configuration file with the given content, or to none when it's empty.
+func mockConfig(t *testing.T, config string) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("GIT_CREDENTIAL_OP_CONFIG", "")
+	if config == "" {
+		return
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Error(err)
+	}
+	t.Setenv("GIT_CREDENTIAL_OP_CONFIG", path)
+}
+
+// setFlags sets the command-line flags for the test and returns their names, like flag.Visit would find them.
+func setFlags(t *testing.T, flags map[string]string) map[string]bool {
+	t.Cleanup(func() {
+		for name := range flags {
+			f := flag.Lookup(name)
+			_ = f.Value.Set(f.DefValue)
+		}
+		locationFlags, referenceFlags = nil, nil
+	})
+	set := map[string]bool{}
+	for name, value := range flags {
+		if err := flag.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+		set[name] = true
+	}
+	return set
+}
+
+func TestNewHelper(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		flags     map[string]string
+		account   string
+		vault     string
+		item      string
+		exclusive bool
+		locations []helper.Location
+		expiry    time.Duration
+		cache     time.Duration
+		err       string
+	}{
+		{
+			name:   "Defaults",
+			expiry: helper.DefaultExpiryWarning,
+		},
+		{
+			name:      "Config",
+			config:    "vault: Personal\nitem: Foo API Key\nexclusive: true\nexpiry_warning: 24h\ncache:\n  ttl: 10m\n",
+			vault:     "Personal",
+			item:      "Foo API Key",
+			exclusive: true,
+			expiry:    24 * time.Hour,
+			cache:     10 * time.Minute,
+		},
+		{
+			name:      "Flags",
+			flags:     map[string]string{"vault": "Engineering", "exclusive": "true", "expiry-warning": "1h", "cache-ttl": "1m"},
+			vault:     "Engineering",
+			exclusive: true,
+			expiry:    time.Hour,
+			cache:     time.Minute,
+		},
+		{
+			name:   "FlagsOverrideConfig",
+			config: "vault: Personal\nitem: Foo API Key\nexclusive: true\ncache:\n  ttl: 10m\n",
+			flags:  map[string]string{"vault": "Engineering", "exclusive": "false", "cache-ttl": "0"},
+			vault:  "Engineering",
+			item:   "Foo API Key",
+			expiry: helper.DefaultExpiryWarning,
+		},
+		{
+			name:      "ConfigLocations",
+			config:    "locations:\n  - account: work.1password.com\n    vault: Team\n  - vault: Personal\n    primary: true\n",
+			locations: []helper.Location{{Account: "work.1password.com", Vault: "Team"}, {Vault: "Personal", Primary: true}},
+			expiry:    helper.DefaultExpiryWarning,
+		},
+		{
+			name:      "LocationFlags",
+			config:    "locations:\n  - account: work.1password.com\n    vault: Team\n",
+			flags:     map[string]string{"location": "Private"},
+			locations: []helper.Location{{Vault: "Private"}},
+			expiry:    helper.DefaultExpiryWarning,
+		},
+		{
+			name:    "AccountFlagNarrowsLocations",
+			config:  "locations:\n  - account: work.1password.com\n    vault: Team\n",
+			flags:   map[string]string{"account": "my.1password.com"},
+			account: "my.1password.com",
+			expiry:  helper.DefaultExpiryWarning,
+		},
+		{
+			name:  "MissingConfig",
+			flags: map[string]string{"config": "/nonexistent/config.yaml"},
+			err:   "open /nonexistent/config.yaml: no such file or directory",
+		},
+		{
+			name:  "InvalidFallback",
+			flags: map[string]string{"fallback": "oldest"},
+			err:   `unknown fallback: "oldest" (expected first, newest, or none)`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockConfig(t, test.config)
+			h, err := newHelper(setFlags(t, test.flags))
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.account, h.Op.Account)
+			assert.Equal(t, test.vault, h.Vault)
+			assert.Equal(t, test.item, h.Item)
+			assert.Equal(t, test.exclusive, h.Exclusive)
+			assert.Equal(t, test.locations, h.Locations)
+			assert.Equal(t, test.expiry, h.ExpiryWarning)
+			if test.cache == 0 {
+				assert.Nil(t, h.Cache)
+			} else {
+				assert.Equal(t, test.cache, h.Cache.TTL)
+			}
+			assert.True(t, h.GitConfig)
+		})
+	}
+}
+
+func TestNewHelperGitConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		flags     map[string]string
+		gitConfig string
+		expect    string
+	}{
+		{
+			name:   "Config",
+			config: "item: Missing\n",
+			expect: "item get Missing --format json --iso-timestamps",
+		},
+		{
+			name:   "FlagOverridesConfig",
+			config: "item: Missing\n",
+			flags:  map[string]string{"item": "Broken"},
+			expect: "item get Broken --format json --iso-timestamps",
+		},
+		{
+			name:      "GitConfigOverridesFlag",
+			config:    "item: Missing\n",
+			flags:     map[string]string{"item": "Broken"},
+			gitConfig: "[credential \"https://foo.com\"]\n\topItem = Foo API Key\n",
+			expect:    "item get Foo API Key --format json --iso-timestamps",
+		},
+		{
+			name:      "GitConfigForOtherRemote",
+			flags:     map[string]string{"item": "Broken"},
+			gitConfig: "[credential \"https://bar.com\"]\n\topItem = Foo API Key\n",
+			expect:    "item get Broken --format json --iso-timestamps",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockConfig(t, test.config)
+			mockGitConfig(t, test.gitConfig)
+			h, err := newHelper(setFlags(t, test.flags))
+			assert.NoError(t, err)
+			op := optest.Mock(t, "helper_get")
+			h.Op.Path = op
+			// the responses don't matter, only which item is asked for
+			_, _ = h.Run(helper.Get, &helper.Attributes{Protocol: "https", Host: "foo.com"})
+			assert.Equal(t, test.expect, optest.Calls(t, op)[0])
+		})
+	}
+}
+
+func TestRemote(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		expect *helper.Attributes
+		err    string
+	}{
+		{
+			name:   "URL",
+			args:   []string{"https://foo.com/bar.git"},
+			expect: &helper.Attributes{Protocol: "https", Host: "foo.com", Path: "bar.git"},
+		},
+		{
+			name: "NoArguments",
+			err:  "expected a single URL argument",
+		},
+		{
+			name: "TooManyArguments",
+			args: []string{"https://foo.com", "https://bar.com"},
+			err:  "expected a single URL argument",
+		},
+		{
+			name: "UnsupportedProtocol",
+			args: []string{"http://foo.com"},
+			err:  `unsupported protocol "http": only https remotes are supported`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attr, err := remote(test.args)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+			assert.Equal(t, test.expect, attr)
+		})
+	}
+}
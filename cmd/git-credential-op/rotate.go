@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/forge"
+	"github.com/gbernady/git-credential-op/pkg/helper"
+)
+
+func runRotate(h *helper.Helper, args []string) error {
+	flags := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	provider := flags.String("provider", "", "the `provider` of the remote: "+strings.Join(forge.Providers, ", ")+"; defaults to the configured provider, detected for the public instances")
+	revoke := flags.Bool("revoke", false, "revoke the old token once the new one is saved")
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	attr, err := remote(flags.Args())
+	if err != nil {
+		return err
+	}
+	res, err := h.Resolve(attr)
+	if err != nil {
+		return err
+	}
+	if res.Item == nil && res.Reference == nil {
+		return fmt.Errorf("no credential found for %s", attr.RemoteURL())
+	}
+	p, err := forge.For(attr.Host, orDefault(*provider, res.Settings.Provider))
+	if err != nil {
+		return err
+	}
+	if res.Item != nil && !*yes {
+		s := res.Summary()
+		answer, err := ask(bufio.NewReader(os.Stdin), fmt.Sprintf("Replace the token in item %q in vault %s with a new one from %s? [y/N]", s.Name(), s.Vault, p.Name()), "")
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			return helper.ErrCanceled
+		}
+	}
+	rot, err := res.Rotate(p, *revoke)
+	if err != nil {
+		if errors.Is(err, helper.ErrNotSaved) {
+			// don't lose the new token, all the more when the provider revoked the old one already
+			fmt.Fprintf(os.Stderr, "The new token is %s; save it in 1Password before it's lost.\n", rot.New.Value)
+		}
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "Rotated token %q for %s\n", rot.New.Name, attr.RemoteURL())
+	if len(rot.New.Scopes) > 0 {
+		fmt.Fprintf(os.Stdout, "Scopes: %s\n", strings.Join(rot.New.Scopes, ", "))
+	}
+	if !rot.New.ExpiresAt.IsZero() {
+		fmt.Fprintf(os.Stdout, "Expires: %s\n", rot.New.ExpiresAt.Format("2006-01-02"))
+	}
+	if rot.Revoked {
+		fmt.Fprintf(os.Stdout, "The old token was revoked.\n")
+	} else {
+		fmt.Fprintf(os.Stdout, "The old token remains valid; it's kept in the item history.\n")
+	}
+	return nil
+}
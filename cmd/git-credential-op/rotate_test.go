@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/helper"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockStdin replaces stdin with the given input for the test.
+func mockStdin(t *testing.T, input string) {
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0600); err != nil {
+		t.Error(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Error(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		f.Close()
+	})
+}
+
+func TestRunRotate(t *testing.T) {
+	ref, err := helper.ParseReference("baz.com=op://Personal/Baz/token")
+	assert.NoError(t, err)
+	tests := []struct {
+		name       string
+		args       []string
+		references []helper.Reference
+		stdin      string
+		err        string
+	}{
+		{
+			name: "NoCredential",
+			args: []string{"https://qux.com"},
+			err:  "no credential found for https://qux.com/",
+		},
+		{
+			name: "UnknownProvider",
+			args: []string{"https://bar.com"},
+			err:  "unknown provider for bar.com: expected one of github, gitlab, gitea",
+		},
+		{
+			name: "InvalidProvider",
+			args: []string{"--provider", "bitbucket", "https://bar.com"},
+			err:  `unknown provider "bitbucket": expected one of github, gitlab, gitea`,
+		},
+		{
+			name:  "Declined",
+			args:  []string{"--provider", "gitea", "https://bar.com"},
+			stdin: "n\n",
+			err:   helper.ErrCanceled.Error(),
+		},
+		{
+			name:  "NoAnswer",
+			args:  []string{"--provider", "gitea", "https://bar.com"},
+			stdin: "",
+			err:   helper.ErrCanceled.Error(),
+		},
+		{
+			name:       "Reference",
+			args:       []string{"--provider", "gitea", "https://baz.com"},
+			references: []helper.Reference{ref},
+			err:        "credential is served by the secret reference " + ref.String() + "; rotate the token of the item it refers to instead",
+		},
+		{
+			name: "InvalidURL",
+			args: []string{"--provider", "gitea"},
+			err:  "expected a single URL argument",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_rotate")
+			mockStdin(t, test.stdin)
+			h := &helper.Helper{Op: opcli.CLI{Path: op}, References: test.references}
+			assert.EqualError(t, runRotate(h, test.args), test.err)
+			for _, c := range optest.Calls(t, op) {
+				// the token is never replaced
+				assert.NotContains(t, c, "item edit")
+			}
+		})
+	}
+}
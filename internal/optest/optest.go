@@ -0,0 +1,61 @@
+// Package optest sets up the `op` mock in testdata/op.sh for the tests of the helper and the command.
+package optest
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// testdata returns the path of the testdata directory at the root of the module.
+func testdata() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "../../testdata")
+}
+
+// Mock sets up the `op` mock responding with all responses from the given fixture directory, and returns its path.
+func Mock(t *testing.T, fixture string) string {
+	tmp := t.TempDir()
+
+	// copy `op` mock
+	b, err := os.ReadFile(filepath.Join(testdata(), "op.sh"))
+	if err != nil {
+		t.Error(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "op"), b, 0744); err != nil {
+		t.Error(err)
+	}
+
+	// copy test responses
+	if err := os.Mkdir(filepath.Join(tmp, "op_responses"), 0755); err != nil {
+		t.Error(err)
+	}
+	files, err := os.ReadDir(filepath.Join(testdata(), "fixtures", fixture))
+	if err != nil {
+		t.Error(err)
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(filepath.Join(testdata(), "fixtures", fixture, f.Name()))
+		if err != nil {
+			t.Error(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmp, "op_responses", f.Name()), b, 0644); err != nil {
+			t.Error(err)
+		}
+	}
+
+	return filepath.Join(tmp, "op")
+}
+
+// Calls returns the arguments of all `op` mock invocations so far.
+func Calls(t *testing.T, op string) []string {
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(op), "op_calls"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		t.Error(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
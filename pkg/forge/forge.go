@@ -0,0 +1,169 @@
+// Package forge manages access tokens through the APIs of git hosting services (forges).
+package forge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Token describes an access token.
+type Token struct {
+	// Value is the secret, only known for newly created tokens.
+	Value string
+
+	Name   string
+	Scopes []string
+
+	// ExpiresAt is the time the token expires, or zero when it never does.
+	ExpiresAt time.Time
+}
+
+// Rotation is the outcome of replacing a token.
+type Rotation struct {
+	Old Token
+	New Token
+
+	// Revoked reports whether the old token was revoked, which some providers do as part of the rotation.
+	Revoked bool
+}
+
+// ErrInvalidToken is returned when the service rejects the token, e.g. because it expired or was revoked.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Provider checks, creates, and revokes access tokens of a git hosting service.
+type Provider interface {
+	// Name returns the name of the service, e.g. GitLab.
+	Name() string
+
+	// Check returns the details the service reports for the token of the user, without its value,
+	// or an error satisfying errors.Is(err, ErrInvalidToken) when the token is rejected.
+	Check(username, token string) (*Token, error)
+
+	// Rotate creates a new token with the same name and scopes as the token of the user.
+	Rotate(username, token string) (*Rotation, error)
+
+	// Revoke revokes the token of the user.
+	Revoke(username, token string) error
+}
+
+// Providers lists the names accepted by For.
+var Providers = []string{"github", "gitlab", "gitea"}
+
+// For returns the provider of the host, which is either named (see Providers) or
+// detected for the public instances (github.com, gitlab.com, and codeberg.org).
+func For(host, name string) (Provider, error) {
+	if name == "" {
+		switch host {
+		case "github.com":
+			name = "github"
+		case "gitlab.com":
+			name = "gitlab"
+		case "codeberg.org":
+			name = "gitea"
+		default:
+			return nil, fmt.Errorf("unknown provider for %s: expected one of %s", host, strings.Join(Providers, ", "))
+		}
+	}
+	switch strings.ToLower(name) {
+	case "github":
+		if host == "github.com" {
+			return &GitHub{BaseURL: "https://api.github.com"}, nil
+		}
+		return &GitHub{BaseURL: "https://" + host + "/api/v3"}, nil
+	case "gitlab":
+		return &GitLab{BaseURL: "https://" + host + "/api/v4"}, nil
+	case "gitea":
+		return &Gitea{BaseURL: "https://" + host + "/api/v1"}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q: expected one of %s", name, strings.Join(Providers, ", "))
+	}
+}
+
+// now returns the current time; replaced in tests.
+var now = time.Now
+
+// DefaultTimeout limits the API calls of providers without their own HTTP client,
+// so that an unreachable service doesn't block git.
+const DefaultTimeout = 5 * time.Second
+
+// defaultClient is the HTTP client of providers without their own.
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// apiError is returned for unsuccessful API responses.
+type apiError struct {
+	service string
+	code    int
+	status  string
+	message string
+}
+
+// Is reports unauthorized responses as ErrInvalidToken.
+func (e *apiError) Is(target error) bool {
+	return target == ErrInvalidToken && e.code == http.StatusUnauthorized
+}
+
+func (e *apiError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("%s API: %s", e.service, e.status)
+	}
+	return fmt.Sprintf("%s API: %s: %s", e.service, e.status, e.message)
+}
+
+// newRequest returns a request with the body encoded as JSON unless it's nil.
+func newRequest(method, url string, body any) (*http.Request, error) {
+	if body == nil {
+		return http.NewRequest(method, url, nil)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequest(method, url, bytes.NewReader(b))
+}
+
+// call sends the request, decoding the JSON response into v unless it's nil.
+func call(client *http.Client, service string, req *http.Request, v any) (*http.Response, error) {
+	if client == nil {
+		client = defaultClient
+	}
+	req.Header.Set("Accept", "application/json")
+	if req.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}
+		_ = json.Unmarshal(b, &body)
+		return nil, &apiError{service: service, code: resp.StatusCode, status: resp.Status, message: orDefault(body.Message, body.Error)}
+	}
+	if v != nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			return nil, fmt.Errorf("%s API: invalid response: %w", service, err)
+		}
+	}
+	return resp, nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
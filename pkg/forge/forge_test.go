@@ -0,0 +1,65 @@
+package forge
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		host   string
+		name   string
+		expect Provider
+		err    string
+	}{
+		{host: "github.com", expect: &GitHub{BaseURL: "https://api.github.com"}},
+		{host: "gitlab.com", expect: &GitLab{BaseURL: "https://gitlab.com/api/v4"}},
+		{host: "codeberg.org", expect: &Gitea{BaseURL: "https://codeberg.org/api/v1"}},
+		{host: "github.example.com", name: "github", expect: &GitHub{BaseURL: "https://github.example.com/api/v3"}},
+		{host: "gitlab.example.com", name: "GitLab", expect: &GitLab{BaseURL: "https://gitlab.example.com/api/v4"}},
+		{host: "git.example.com:3000", name: "gitea", expect: &Gitea{BaseURL: "https://git.example.com:3000/api/v1"}},
+		{host: "git.example.com", err: "unknown provider for git.example.com: expected one of github, gitlab, gitea"},
+		{host: "git.example.com", name: "bitbucket", err: `unknown provider "bitbucket": expected one of github, gitlab, gitea`},
+	}
+	for _, test := range tests {
+		t.Run(test.host+"/"+test.name, func(t *testing.T) {
+			p, err := For(test.host, test.name)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, p)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+// fixedNow sets the current time for the test.
+func fixedNow(t *testing.T, tm time.Time) {
+	now = func() time.Time { return tm }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestCallTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+	client := defaultClient
+	defaultClient = &http.Client{Timeout: 50 * time.Millisecond}
+	defer func() { defaultClient = client }()
+
+	p := &GitLab{BaseURL: srv.URL}
+	_, err := p.Check("", "glpat-old")
+	var ne net.Error
+	assert.True(t, errors.As(err, &ne) && ne.Timeout(), "expected a timeout, got %v", err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,105 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+)
+
+// Gitea rotates access tokens using the Gitea (and Forgejo) REST API.
+// See https://docs.gitea.com/development/api-usage.
+//
+// Gitea only manages tokens with basic authentication, so the token must be allowed
+// to authenticate that way and have the write:user scope. Since token names are unique,
+// the new token is named after the old one with the date of the rotation.
+type Gitea struct {
+	// BaseURL is the URL of the API, e.g. https://codeberg.org/api/v1.
+	BaseURL string
+
+	// Client is the HTTP client to use; defaults to a client with DefaultTimeout.
+	Client *http.Client
+}
+
+// giteaToken is a token in the Gitea API.
+type giteaToken struct {
+	ID             int64    `json:"id"`
+	Name           string   `json:"name"`
+	Scopes         []string `json:"scopes"`
+	SHA1           string   `json:"sha1"`
+	TokenLastEight string   `json:"token_last_eight"`
+}
+
+func (t giteaToken) token() Token {
+	return Token{Value: t.SHA1, Name: t.Name, Scopes: t.Scopes}
+}
+
+// rotatedSuffix matches the suffix added to the names of rotated tokens.
+var rotatedSuffix = regexp.MustCompile(`\s*\(rotated \d{4}-\d{2}-\d{2}\)$`)
+
+func (g *Gitea) Name() string {
+	return "Gitea"
+}
+
+// Check only verifies the token, since Gitea tokens never expire and their scopes are only listed
+// with basic authentication, which may not be allowed for the token.
+func (g *Gitea) Check(username, token string) (*Token, error) {
+	if err := g.call(http.MethodGet, "/user", username, token, nil, nil); err != nil {
+		return nil, err
+	}
+	return &Token{}, nil
+}
+
+func (g *Gitea) Rotate(username, token string) (*Rotation, error) {
+	old, err := g.find(username, token)
+	if err != nil {
+		return nil, err
+	}
+	body := struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}{
+		Name:   fmt.Sprintf("%s (rotated %s)", rotatedSuffix.ReplaceAllString(old.Name, ""), now().Format(dateLayout)),
+		Scopes: old.Scopes,
+	}
+	var created giteaToken
+	if err := g.call(http.MethodPost, "/users/"+url.PathEscape(username)+"/tokens", username, token, body, &created); err != nil {
+		return nil, err
+	}
+	return &Rotation{Old: old.token(), New: created.token()}, nil
+}
+
+func (g *Gitea) Revoke(username, token string) error {
+	old, err := g.find(username, token)
+	if err != nil {
+		return err
+	}
+	return g.call(http.MethodDelete, fmt.Sprintf("/users/%s/tokens/%d", url.PathEscape(username), old.ID), username, token, nil, nil)
+}
+
+// find returns the token among the tokens of the user, identified by its last eight characters.
+func (g *Gitea) find(username, token string) (*giteaToken, error) {
+	if username == "" {
+		return nil, fmt.Errorf("%s API: username is required", g.Name())
+	}
+	var tokens []giteaToken
+	if err := g.call(http.MethodGet, "/users/"+url.PathEscape(username)+"/tokens", username, token, nil, &tokens); err != nil {
+		return nil, err
+	}
+	for i, t := range tokens {
+		if len(token) >= 8 && t.TokenLastEight == token[len(token)-8:] {
+			return &tokens[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s API: token not found among the tokens of %s", g.Name(), username)
+}
+
+func (g *Gitea) call(method, path, username, token string, body any, v any) error {
+	req, err := newRequest(method, g.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(username, token)
+	_, err = call(g.Client, g.Name(), req, v)
+	return err
+}
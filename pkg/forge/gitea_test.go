@@ -0,0 +1,95 @@
+package forge
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// giteaServer serves the token API of a Gitea user with two tokens, the second one being used.
+func giteaServer(t *testing.T, requests *[]string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		if u, p, ok := r.BasicAuth(); !ok || u != "qux" || p != "0123456789abcdef0123456789abcdef01234567" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"auth required"}`)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/users/qux/tokens":
+			io.WriteString(w, `[{"id":1,"name":"laptop","scopes":["read:repository"],"sha1":"","token_last_eight":"ffffffff"},{"id":7,"name":"ci (rotated 2026-01-02)","scopes":["write:repository","write:user"],"sha1":"","token_last_eight":"01234567"}]`)
+		case "POST /api/v1/users/qux/tokens":
+			b, _ := io.ReadAll(r.Body)
+			assert.JSONEq(t, `{"name":"ci (rotated 2026-10-19)","scopes":["write:repository","write:user"]}`, string(b))
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"id":8,"name":"ci (rotated 2026-10-19)","scopes":["write:repository","write:user"],"sha1":"fedcba9876543210fedcba9876543210fedcba98","token_last_eight":"fedcba98"}`)
+		case "GET /api/v1/user":
+			io.WriteString(w, `{"id":1,"login":"qux"}`)
+		case "DELETE /api/v1/users/qux/tokens/7":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGiteaRotate(t *testing.T) {
+	fixedNow(t, time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC))
+	var requests []string
+	srv := giteaServer(t, &requests)
+	g := &Gitea{BaseURL: srv.URL + "/api/v1", Client: srv.Client()}
+	rot, err := g.Rotate("qux", "0123456789abcdef0123456789abcdef01234567")
+	assert.NoError(t, err)
+	assert.Equal(t, &Rotation{
+		Old: Token{Name: "ci (rotated 2026-01-02)", Scopes: []string{"write:repository", "write:user"}},
+		New: Token{Value: "fedcba9876543210fedcba9876543210fedcba98", Name: "ci (rotated 2026-10-19)", Scopes: []string{"write:repository", "write:user"}},
+	}, rot)
+	assert.Equal(t, []string{"GET /api/v1/users/qux/tokens", "POST /api/v1/users/qux/tokens"}, requests)
+}
+
+func TestGiteaRevoke(t *testing.T) {
+	var requests []string
+	srv := giteaServer(t, &requests)
+	g := &Gitea{BaseURL: srv.URL + "/api/v1", Client: srv.Client()}
+	assert.NoError(t, g.Revoke("qux", "0123456789abcdef0123456789abcdef01234567"))
+	assert.Equal(t, []string{"GET /api/v1/users/qux/tokens", "DELETE /api/v1/users/qux/tokens/7"}, requests)
+}
+
+func TestGiteaErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		token    string
+		err      string
+	}{
+		{"MissingUsername", "", "0123456789abcdef0123456789abcdef01234567", "Gitea API: username is required"},
+		{"Unauthorized", "qux", "wrong", "Gitea API: 401 Unauthorized: auth required"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests []string
+			srv := giteaServer(t, &requests)
+			g := &Gitea{BaseURL: srv.URL + "/api/v1", Client: srv.Client()}
+			_, err := g.Rotate(test.username, test.token)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestGiteaCheck(t *testing.T) {
+	var requests []string
+	srv := giteaServer(t, &requests)
+	g := &Gitea{BaseURL: srv.URL + "/api/v1", Client: srv.Client()}
+	tok, err := g.Check("qux", "0123456789abcdef0123456789abcdef01234567")
+	assert.NoError(t, err)
+	assert.Equal(t, &Token{}, tok)
+	_, err = g.Check("qux", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, []string{"GET /api/v1/user", "GET /api/v1/user"}, requests)
+}
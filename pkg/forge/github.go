@@ -0,0 +1,83 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitHub checks personal access tokens using the GitHub REST API.
+//
+// GitHub has no API to create personal access tokens, so Rotate fails with a link
+// to create a new one with the same scopes on the website instead.
+type GitHub struct {
+	// BaseURL is the URL of the API, e.g. https://api.github.com.
+	BaseURL string
+
+	// Client is the HTTP client to use; defaults to a client with DefaultTimeout.
+	Client *http.Client
+}
+
+func (g *GitHub) Name() string {
+	return "GitHub"
+}
+
+// Check reports the scopes of classic tokens and the expiry of tokens that expire.
+func (g *GitHub) Check(username, token string) (*Token, error) {
+	req, err := newRequest(http.MethodGet, g.BaseURL+"/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := call(g.Client, g.Name(), req, nil)
+	if err != nil {
+		return nil, err
+	}
+	t := &Token{}
+	if v := resp.Header.Get("X-OAuth-Scopes"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			t.Scopes = append(t.Scopes, strings.TrimSpace(s))
+		}
+	}
+	if v := resp.Header.Get("GitHub-Authentication-Token-Expiration"); v != "" {
+		for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+			if exp, err := time.Parse(layout, v); err == nil {
+				t.ExpiresAt = exp.UTC()
+				break
+			}
+		}
+	}
+	return t, nil
+}
+
+func (g *GitHub) Rotate(username, token string) (*Rotation, error) {
+	t, err := g.Check(username, token)
+	if err != nil {
+		return nil, err
+	}
+	web := g.webURL()
+	// only classic tokens report their scopes; fine-grained tokens have permissions instead
+	if len(t.Scopes) == 0 {
+		return nil, fmt.Errorf("%s has no API to create tokens: create a fine-grained token at %s/settings/personal-access-tokens/new", g.Name(), web)
+	}
+	q := url.Values{"scopes": {strings.Join(t.Scopes, ",")}, "description": {"git-credential-op"}}
+	return nil, fmt.Errorf("%s has no API to create tokens: create one with the scopes %s at %s/settings/tokens/new?%s", g.Name(), strings.Join(t.Scopes, ", "), web, q.Encode())
+}
+
+func (g *GitHub) Revoke(username, token string) error {
+	return fmt.Errorf("%s has no API to revoke tokens", g.Name())
+}
+
+// webURL returns the URL of the website serving the API.
+func (g *GitHub) webURL() string {
+	u, err := url.Parse(g.BaseURL)
+	if err != nil {
+		return g.BaseURL
+	}
+	if u.Host == "api.github.com" {
+		return "https://github.com"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,119 @@
+package forge
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubRotate(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes string
+		err    string
+	}{
+		{
+			name:   "Classic",
+			scopes: "repo, read:org",
+			err:    "GitHub has no API to create tokens: create one with the scopes repo, read:org at https://github.example.com/settings/tokens/new?description=git-credential-op&scopes=repo%2Cread%3Aorg",
+		},
+		{
+			name: "FineGrained",
+			err:  "GitHub has no API to create tokens: create a fine-grained token at https://github.example.com/settings/personal-access-tokens/new",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v3/user", r.URL.Path)
+				assert.Equal(t, "Bearer ghp_old", r.Header.Get("Authorization"))
+				if test.scopes != "" {
+					w.Header().Set("X-OAuth-Scopes", test.scopes)
+				}
+				io.WriteString(w, `{"login":"qux"}`)
+			}))
+			defer srv.Close()
+			// the website is derived from the API URL, so rewrite the host of the test server
+			client := srv.Client()
+			client.Transport = rewriteHost{srv.Listener.Addr().String(), client.Transport}
+			g := &GitHub{BaseURL: "https://github.example.com/api/v3", Client: client}
+			rot, err := g.Rotate("qux", "ghp_old")
+			assert.Nil(t, rot)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestGitHubWebURL(t *testing.T) {
+	assert.Equal(t, "https://github.com", (&GitHub{BaseURL: "https://api.github.com"}).webURL())
+	assert.Equal(t, "https://github.example.com", (&GitHub{BaseURL: "https://github.example.com/api/v3"}).webURL())
+}
+
+// rewriteHost sends all requests to the host.
+type rewriteHost struct {
+	host string
+	next http.RoundTripper
+}
+
+func (rt rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Host = rt.host
+	return rt.next.RoundTrip(req)
+}
+
+func TestGitHubCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		headers map[string]string
+		expect  *Token
+		invalid bool
+	}{
+		{
+			name:    "Classic",
+			token:   "ghp_old",
+			headers: map[string]string{"X-OAuth-Scopes": "repo, read:org", "GitHub-Authentication-Token-Expiration": "2027-01-17 12:00:00 UTC"},
+			expect:  &Token{Scopes: []string{"repo", "read:org"}, ExpiresAt: time.Date(2027, time.January, 17, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "FineGrained",
+			token:   "ghp_old",
+			headers: map[string]string{"GitHub-Authentication-Token-Expiration": "2027-01-17 04:00:00 -0800"},
+			expect:  &Token{ExpiresAt: time.Date(2027, time.January, 17, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "Revoked",
+			token:   "ghp_revoked",
+			invalid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "Bearer ghp_old" {
+					w.WriteHeader(http.StatusUnauthorized)
+					io.WriteString(w, `{"message":"Bad credentials"}`)
+					return
+				}
+				for k, v := range test.headers {
+					w.Header().Set(k, v)
+				}
+				io.WriteString(w, `{"login":"qux"}`)
+			}))
+			defer srv.Close()
+			g := &GitHub{BaseURL: srv.URL, Client: srv.Client()}
+			tok, err := g.Check("qux", test.token)
+			if test.invalid {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				assert.EqualError(t, err, "GitHub API: 401 Unauthorized: Bad credentials")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, tok)
+		})
+	}
+}
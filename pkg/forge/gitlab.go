@@ -0,0 +1,82 @@
+package forge
+
+import (
+	"net/http"
+	"time"
+)
+
+// dateLayout is the format of token expiry dates in the GitLab and Gitea APIs.
+const dateLayout = "2006-01-02"
+
+// GitLab rotates personal, project, and group access tokens using the GitLab REST API.
+// See https://docs.gitlab.com/ee/api/personal_access_tokens.html.
+//
+// GitLab revokes the old token as part of the rotation. The new token keeps the lifetime
+// of the old one, since GitLab would otherwise let it expire after a week.
+type GitLab struct {
+	// BaseURL is the URL of the API, e.g. https://gitlab.com/api/v4.
+	BaseURL string
+
+	// Client is the HTTP client to use; defaults to a client with DefaultTimeout.
+	Client *http.Client
+}
+
+// gitlabToken is a token in the GitLab API.
+type gitlabToken struct {
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt string    `json:"expires_at"`
+}
+
+func (t gitlabToken) token() Token {
+	exp, _ := time.Parse(dateLayout, t.ExpiresAt)
+	return Token{Value: t.Token, Name: t.Name, Scopes: t.Scopes, ExpiresAt: exp}
+}
+
+func (g *GitLab) Name() string {
+	return "GitLab"
+}
+
+func (g *GitLab) Check(username, token string) (*Token, error) {
+	var t gitlabToken
+	if err := g.call(http.MethodGet, "/personal_access_tokens/self", token, nil, &t); err != nil {
+		return nil, err
+	}
+	v := t.token()
+	return &v, nil
+}
+
+func (g *GitLab) Rotate(username, token string) (*Rotation, error) {
+	var old gitlabToken
+	if err := g.call(http.MethodGet, "/personal_access_tokens/self", token, nil, &old); err != nil {
+		return nil, err
+	}
+	var body struct {
+		ExpiresAt string `json:"expires_at,omitempty"`
+	}
+	if exp := old.token().ExpiresAt; !exp.IsZero() {
+		lifetime := exp.Sub(old.CreatedAt.Truncate(24 * time.Hour))
+		body.ExpiresAt = now().Add(lifetime).Format(dateLayout)
+	}
+	var rotated gitlabToken
+	if err := g.call(http.MethodPost, "/personal_access_tokens/self/rotate", token, body, &rotated); err != nil {
+		return nil, err
+	}
+	return &Rotation{Old: old.token(), New: rotated.token(), Revoked: true}, nil
+}
+
+func (g *GitLab) Revoke(username, token string) error {
+	return g.call(http.MethodDelete, "/personal_access_tokens/self", token, nil, nil)
+}
+
+func (g *GitLab) call(method, path, token string, body any, v any) error {
+	req, err := newRequest(method, g.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("PRIVATE-TOKEN", token)
+	_, err = call(g.Client, g.Name(), req, v)
+	return err
+}
@@ -0,0 +1,111 @@
+package forge
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLabRotate(t *testing.T) {
+	tests := []struct {
+		name    string
+		self    string
+		expires string
+		rotated string
+		expect  *Rotation
+		err     string
+	}{
+		{
+			name:    "Expiring",
+			self:    `{"id":42,"name":"ci","scopes":["api","read_repository"],"created_at":"2026-01-10T08:00:00.000Z","expires_at":"2026-04-10"}`,
+			expires: `{"expires_at":"2027-01-17"}`,
+			rotated: `{"id":43,"name":"ci","scopes":["api","read_repository"],"token":"glpat-new","created_at":"2026-10-19T12:00:00.000Z","expires_at":"2027-01-17"}`,
+			expect: &Rotation{
+				Old:     Token{Name: "ci", Scopes: []string{"api", "read_repository"}, ExpiresAt: time.Date(2026, time.April, 10, 0, 0, 0, 0, time.UTC)},
+				New:     Token{Value: "glpat-new", Name: "ci", Scopes: []string{"api", "read_repository"}, ExpiresAt: time.Date(2027, time.January, 17, 0, 0, 0, 0, time.UTC)},
+				Revoked: true,
+			},
+		},
+		{
+			name:    "NeverExpiring",
+			self:    `{"id":42,"name":"ci","scopes":["api"],"created_at":"2022-01-10T08:00:00.000Z","expires_at":null}`,
+			expires: `{}`,
+			rotated: `{"id":43,"name":"ci","scopes":["api"],"token":"glpat-new","created_at":"2026-10-19T12:00:00.000Z","expires_at":"2027-01-17"}`,
+			expect: &Rotation{
+				Old:     Token{Name: "ci", Scopes: []string{"api"}},
+				New:     Token{Value: "glpat-new", Name: "ci", Scopes: []string{"api"}, ExpiresAt: time.Date(2027, time.January, 17, 0, 0, 0, 0, time.UTC)},
+				Revoked: true,
+			},
+		},
+		{
+			name: "Unauthorized",
+			err:  "GitLab API: 401 Unauthorized: 401 Unauthorized",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fixedNow(t, time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC))
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.self == "" || r.Header.Get("PRIVATE-TOKEN") != "glpat-old" {
+					w.WriteHeader(http.StatusUnauthorized)
+					io.WriteString(w, `{"message":"401 Unauthorized"}`)
+					return
+				}
+				switch r.Method + " " + r.URL.Path {
+				case "GET /api/v4/personal_access_tokens/self":
+					io.WriteString(w, test.self)
+				case "POST /api/v4/personal_access_tokens/self/rotate":
+					b, _ := io.ReadAll(r.Body)
+					assert.JSONEq(t, test.expires, string(b))
+					io.WriteString(w, test.rotated)
+				default:
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+			g := &GitLab{BaseURL: srv.URL + "/api/v4", Client: srv.Client()}
+			rot, err := g.Rotate("git", "glpat-old")
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, rot)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestGitLabRevoke(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = r.Method == http.MethodDelete && r.URL.Path == "/api/v4/personal_access_tokens/self" && r.Header.Get("PRIVATE-TOKEN") == "glpat-old"
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	g := &GitLab{BaseURL: srv.URL + "/api/v4", Client: srv.Client()}
+	assert.NoError(t, g.Revoke("git", "glpat-old"))
+	assert.True(t, called)
+}
+
+func TestGitLabCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-old" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+		io.WriteString(w, `{"id":42,"name":"ci","scopes":["api"],"created_at":"2026-01-10T08:00:00.000Z","expires_at":"2026-04-10"}`)
+	}))
+	defer srv.Close()
+	g := &GitLab{BaseURL: srv.URL + "/api/v4", Client: srv.Client()}
+	tok, err := g.Check("git", "glpat-old")
+	assert.NoError(t, err)
+	assert.Equal(t, &Token{Name: "ci", Scopes: []string{"api"}, ExpiresAt: time.Date(2026, time.April, 10, 0, 0, 0, 0, time.UTC)}, tok)
+	_, err = g.Check("git", "glpat-revoked")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
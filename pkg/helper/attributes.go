@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -40,19 +42,51 @@ type Attributes struct {
 
 	// WWWAuth contains WWW-Authenticate authentication headers received by Git with an HTTP response.
 	WWWAuth []string
+
+	// Quit tells git to stop asking other helpers and the user for the credential.
+	Quit bool
+
+	// Extra contains the attributes unknown to the helper, in order, and String writes them back.
+	// Serve doesn't echo them to git: attributes like capability[] and state[] describe what the
+	// sender supports and keeps, so repeating git's own would claim support the helper doesn't have.
+	Extra []Attribute
+}
+
+// Attribute is a named attribute the helper doesn't know, kept for passing it through.
+type Attribute struct {
+	Key   string
+	Value string
 }
 
 // ParseAttributes parses git credential into named attributes.
-func ParseAttributes(r io.Reader) *Attributes {
+//
+// Like git, it reads until an empty line or the end of input, and an empty value
+// of an array attribute (e.g., wwwauth[]=) clears the values before it.
+// Unknown attributes are kept in Extra. It fails on lines that are not key=value pairs,
+// keys or values with NUL or carriage return characters, and attributes given more than once.
+func ParseAttributes(r io.Reader) (*Attributes, error) {
 	a := &Attributes{}
+	seen := map[string]bool{}
 	s := bufio.NewScanner(r)
-	for s.Scan() {
-		v := strings.SplitN(s.Text(), "=", 2)
-		if len(v) != 2 {
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if line == "" {
+			break
+		}
+		key, val, ok := strings.Cut(line, "=")
+		switch {
+		case !ok || key == "":
+			return nil, fmt.Errorf("line %d: invalid attribute %q: expected key=value", n, line)
+		case strings.ContainsAny(line, "\x00\r"):
+			return nil, fmt.Errorf("line %d: attribute %q contains a control character", n, key)
+		}
+		if array := strings.HasSuffix(key, "[]"); !array && seen[key] {
+			return nil, fmt.Errorf("line %d: duplicate attribute %q", n, key)
+		} else if array && val == "" {
+			a.reset(key)
 			continue
 		}
-		key := v[0]
-		val := strings.TrimSuffix(v[1], "\n")
+		seen[key] = true
 		switch key {
 		case "protocol":
 			a.Protocol = val
@@ -65,7 +99,13 @@ func ParseAttributes(r io.Reader) *Attributes {
 		case "password":
 			a.Password = val
 		case "password_expiry_utc":
-			v, _ := strconv.ParseInt(val, 10, 0)
+			if val == "" {
+				continue
+			}
+			v, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || v < 0 {
+				return nil, fmt.Errorf("line %d: invalid password_expiry_utc %q: expected a Unix timestamp", n, val)
+			}
 			a.PasswordExpiry = time.Unix(v, 0)
 		case "oauth_refresh_token":
 			a.OAuthRefreshToken = val
@@ -73,11 +113,29 @@ func ParseAttributes(r io.Reader) *Attributes {
 			a.URL = val
 		case "wwwauth[]":
 			a.WWWAuth = append(a.WWWAuth, val)
+		case "quit":
+			a.Quit = parseBool(val)
 		default:
-			continue
+			a.Extra = append(a.Extra, Attribute{Key: key, Value: val})
 		}
 	}
-	return a
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// reset clears the values of the array attribute.
+func (a *Attributes) reset(key string) {
+	if key == "wwwauth[]" {
+		a.WWWAuth = nil
+		return
+	}
+	extra := slices.DeleteFunc(a.Extra, func(e Attribute) bool { return e.Key == key })
+	if len(extra) == 0 {
+		extra = nil
+	}
+	a.Extra = extra
 }
 
 func (a *Attributes) String() string {
@@ -109,18 +167,114 @@ func (a *Attributes) String() string {
 	for _, v := range a.WWWAuth {
 		fmt.Fprintf(&b, "wwwauth[]=%s\n", v)
 	}
+	if a.Quit {
+		b.WriteString("quit=1\n")
+	}
+	for _, e := range a.Extra {
+		fmt.Fprintf(&b, "%s=%s\n", e.Key, e.Value)
+	}
 	return b.String()
 }
 
-// Match checks if attributes match a given opcli item.
+// parseBool parses a boolean value the way git does (e.g., 1, true, yes, or on).
+func parseBool(s string) bool {
+	switch strings.ToLower(s) {
+	case "true", "yes", "on":
+		return true
+	}
+	v, err := strconv.Atoi(s)
+	return err == nil && v != 0
+}
+
+// ParseURL returns the attributes describing the remote URL (e.g., https://github.com/org/repo.git).
+func ParseURL(s string) (*Attributes, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: expected protocol://host[/path]", s)
+	}
+	a := &Attributes{
+		Protocol: u.Scheme,
+		Host:     u.Host,
+		Path:     strings.TrimPrefix(u.Path, "/"),
+	}
+	if u.User != nil {
+		a.Username = u.User.Username()
+	}
+	return a, nil
+}
+
+// RemoteURL returns the URL of the remote the credential is used for.
+func (a *Attributes) RemoteURL() string {
+	if a.URL != "" {
+		return a.URL
+	}
+	u := url.URL{
+		Scheme: a.Protocol,
+		Host:   a.Host,
+		Path:   "/" + strings.TrimPrefix(a.Path, "/"),
+	}
+	return u.String()
+}
+
+// redactedURL returns the remote URL without the userinfo git may have been given in the url attribute, for logging.
+func (a *Attributes) redactedURL() string {
+	u, err := url.Parse(a.RemoteURL())
+	if err != nil {
+		return "(invalid url)"
+	}
+	u.User = nil
+	return u.String()
+}
+
+// Match checks if attributes match a given opcli item using the default field mapping (see MatchFields).
 func (a *Attributes) Match(item *opcli.Item) bool {
+	return a.MatchFields(item, DefaultFields)
+}
+
+// MatchFields checks if attributes match a given opcli item.
+//
+// The host and path are read from the item's hostname and path fields according to the mapping,
+// or from tags in the host:<hostname> and path:<path> format. Items tagged auth:<scheme> or realm:<realm>
+// only match when git received a matching authentication challenge (see Challenges).
+func (a *Attributes) MatchFields(item *opcli.Item, fields Fields) bool {
+	return a.mismatch(item, fields) == ""
+}
+
+// mismatch explains why the attributes don't match the item, or returns an empty string when they do.
+func (a *Attributes) mismatch(item *opcli.Item, fields Fields) string {
+	hosts, paths := matchValues(item, DefaultFields.Merge(fields))
 	// required
-	if f := item.Field("hostname"); f == nil || f.Value == "" || f.Value != a.Host {
-		return false
+	switch {
+	case len(hosts) == 0:
+		return "no hostname"
+	case !slices.Contains(hosts, a.Host):
+		return fmt.Sprintf("host %s not in %s", a.Host, strings.Join(hosts, ","))
+	}
+	// optional; Git LFS endpoints match the path of their repository too
+	if len(paths) > 0 && !slices.Contains(paths, a.Path) && !(a.IsLFS() && slices.Contains(paths, a.repoPath())) {
+		return fmt.Sprintf("path %q not in %s", a.Path, strings.Join(paths, ","))
+	}
+	return a.challengeMismatch(item)
+}
+
+// matchValues returns all non-empty hosts and paths the item declares.
+func matchValues(item *opcli.Item, fields Fields) (hosts []string, paths []string) {
+	if f := fields.hostname(item); f != nil {
+		hosts = append(hosts, f.Value)
+	}
+	if f := fields.path(item); f != nil {
+		paths = append(paths, f.Value)
 	}
-	// optional
-	if f := item.Field("path"); f != nil && f.Value != "" && f.Value != a.Path {
-		return false
+	for _, t := range item.Tags {
+		if v, ok := strings.CutPrefix(t, "host:"); ok && v != "" {
+			hosts = append(hosts, v)
+		}
+		if v, ok := strings.CutPrefix(t, "path:"); ok && v != "" {
+			paths = append(paths, v)
+		}
 	}
-	return true
+	return hosts, paths
 }
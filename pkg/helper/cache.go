@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Cache is an on-disk cache of item metadata used to pick the matching item without listing
+// and fetching every item on each request.
+//
+// The cache never holds secret values. Items are stored as returned by metadata(),
+// and entries are invalidated whenever the item version reported by 1Password changes.
+type Cache struct {
+	// Path is the location of the cache file.
+	Path string
+
+	// TTL specifies how long the cached listings are considered fresh.
+	TTL time.Duration
+}
+
+// cacheVersion is the version of the cached metadata format; caches in other versions are ignored.
+const cacheVersion = 1
+
+type cacheScope struct {
+	Version   int          `json:"version"`
+	CreatedAt time.Time    `json:"created_at"`
+	Items     []opcli.Item `json:"items"`
+}
+
+// DefaultCachePath returns the default location of the cache file within the user cache directory.
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "git-credential-op", "items.json"), nil
+}
+
+// Load returns cached items for the given scope and whether they are still fresh.
+// Any error reading the cache is treated as a cache miss.
+func (c *Cache) Load(scope string) ([]opcli.Item, bool) {
+	data, err := c.read()
+	if err != nil {
+		return nil, false
+	}
+	s, ok := data[scope]
+	if !ok || s.Version != cacheVersion {
+		return nil, false
+	}
+	return s.Items, time.Since(s.CreatedAt) < c.TTL
+}
+
+// Store replaces cached items for the given scope.
+func (c *Cache) Store(scope string, items []opcli.Item) error {
+	data, err := c.read()
+	if err != nil {
+		data = map[string]cacheScope{}
+	}
+	data[scope] = cacheScope{
+		Version:   cacheVersion,
+		CreatedAt: time.Now(),
+		Items:     items,
+	}
+	return c.write(data)
+}
+
+// Invalidate removes cached items for the given scope.
+func (c *Cache) Invalidate(scope string) error {
+	data, err := c.read()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	delete(data, scope)
+	return c.write(data)
+}
+
+func (c *Cache) read() (map[string]cacheScope, error) {
+	b, err := os.ReadFile(c.Path)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]cacheScope
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = map[string]cacheScope{}
+	}
+	return data, nil
+}
+
+func (c *Cache) write(data map[string]cacheScope) error {
+	return writeJSON(c.Path, data)
+}
+
+// opVersion is the cached version of the 1Password CLI executable, valid while the executable is unchanged.
+type opVersion struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Version string    `json:"version"`
+}
+
+// DetectOp records the installed version of 1Password CLI like opcli.CLI.Detect, but reuses the version
+// cached at path while the executable is unchanged, so that git requests don't run op --version every time.
+// The path is usually next to the metadata cache file (see DefaultCachePath); it's read and written even
+// when the metadata cache is disabled, as the version is no secret and doesn't go stale.
+func DetectOp(op *opcli.CLI, path string) error {
+	exe, err := op.Executable()
+	if err != nil {
+		return op.Detect()
+	}
+	fi, err := os.Stat(exe)
+	if err != nil {
+		return op.Detect()
+	}
+	cur := opVersion{Path: exe, Size: fi.Size(), ModTime: fi.ModTime()}
+	var cached opVersion
+	if b, err := os.ReadFile(path); err == nil && json.Unmarshal(b, &cached) == nil &&
+		cached.Path == cur.Path && cached.Size == cur.Size && cached.ModTime.Equal(cur.ModTime) {
+		if v, err := opcli.ParseVersion(cached.Version); err == nil {
+			return op.Assume(v)
+		}
+	}
+	err = op.Detect()
+	if op.Installed != nil {
+		cur.Version = op.Installed.String()
+		// failing to cache the version only costs running op --version again
+		_ = writeJSON(path, cur)
+	}
+	return err
+}
+
+// writeJSON atomically replaces the file with the JSON encoding of v, readable by the user only.
+func writeJSON(path string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// write to a temporary file first so concurrent readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// metadata returns a copy of the item stripped down to the non-secret values used for matching,
+// keeping the hostname, path, and expiry fields of each credential the item holds.
+func metadata(item *opcli.Item, fields Fields) opcli.Item {
+	m := opcli.Item{
+		ID:        item.ID,
+		Title:     item.Title,
+		Tags:      item.Tags,
+		Version:   item.Version,
+		Vault:     item.Vault,
+		Category:  item.Category,
+		UpdatedAt: item.UpdatedAt,
+		URLs:      item.URLs,
+	}
+	if len(item.Sections) > 0 {
+		m.Sections = item.Sections
+	}
+	seen := map[opcli.Field]bool{}
+	var creds []*opcli.Item
+	for _, c := range credentials(item, fields) {
+		creds = append(creds, c)
+		if _, server := lfsCredentials(item, c, fields); server != nil {
+			creds = append(creds, server)
+		}
+	}
+	for _, c := range creds {
+		for _, f := range []*opcli.Field{fields.hostname(c), fields.path(c), fields.expiry(c)} {
+			if f == nil {
+				continue
+			}
+			v := opcli.Field{
+				ID:      f.ID,
+				Section: f.Section,
+				Type:    f.Type,
+				Label:   f.Label,
+				Value:   f.Value,
+			}
+			if !seen[v] {
+				seen[v] = true
+				m.Fields = append(m.Fields, v)
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,159 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	c := &Cache{Path: filepath.Join(t.TempDir(), "cache", "items.json"), TTL: time.Minute}
+
+	items, fresh := c.Load("foo")
+	assert.Nil(t, items)
+	assert.False(t, fresh)
+
+	foo := []opcli.Item{
+		{
+			ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+			Title:    "Foo API Key",
+			Version:  3,
+			Category: opcli.CategoryAPICredential,
+			Fields: []opcli.Field{
+				{
+					ID:    "hostname",
+					Type:  opcli.FieldTypeString,
+					Label: "hostname",
+					Value: "foo.com",
+				},
+			},
+		},
+	}
+	assert.NoError(t, c.Store("foo", foo))
+	assert.NoError(t, c.Store("bar", nil))
+
+	items, fresh = c.Load("foo")
+	assert.Equal(t, foo, items)
+	assert.True(t, fresh)
+
+	assert.NoError(t, c.Invalidate("foo"))
+	items, fresh = c.Load("foo")
+	assert.Nil(t, items)
+	assert.False(t, fresh)
+	_, fresh = c.Load("bar")
+	assert.True(t, fresh)
+
+	c.TTL = 0
+	_, fresh = c.Load("bar")
+	assert.False(t, fresh)
+}
+
+func TestDetectOp(t *testing.T) {
+	op := optest.Mock(t, "helper_version")
+	path := filepath.Join(t.TempDir(), "cache", "op.json")
+
+	cli := &opcli.CLI{Path: op}
+	assert.NoError(t, DetectOp(cli, path))
+	assert.Equal(t, &opcli.Version{Major: 2, Minor: 24}, cli.Installed)
+	assert.Equal(t, []string{"--version"}, optest.Calls(t, op))
+
+	// the cached version is used while op is unchanged
+	cli = &opcli.CLI{Path: op}
+	assert.NoError(t, DetectOp(cli, path))
+	assert.Equal(t, &opcli.Version{Major: 2, Minor: 24}, cli.Installed)
+	assert.Equal(t, []string{"--version"}, optest.Calls(t, op))
+
+	// and detected again once op is replaced
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(op, later, later))
+	cli = &opcli.CLI{Path: op}
+	assert.NoError(t, DetectOp(cli, path))
+	assert.Equal(t, &opcli.Version{Major: 2, Minor: 24}, cli.Installed)
+	assert.Equal(t, []string{"--version", "--version"}, optest.Calls(t, op))
+
+	// a cached unsupported version is still reported
+	fi, err := os.Stat(op)
+	assert.NoError(t, err)
+	assert.NoError(t, writeJSON(path, opVersion{Path: op, Size: fi.Size(), ModTime: fi.ModTime(), Version: "1.12.4"}))
+	cli = &opcli.CLI{Path: op}
+	assert.ErrorIs(t, DetectOp(cli, path), opcli.ErrUnsupportedVersion)
+	assert.Equal(t, &opcli.Version{Major: 1, Minor: 12, Patch: 4}, cli.Installed)
+	assert.Len(t, optest.Calls(t, op), 2)
+}
+
+func TestMetadata(t *testing.T) {
+	item := &opcli.Item{
+		ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title:    "Foo API Key",
+		Version:  3,
+		Category: opcli.CategoryAPICredential,
+		Fields: []opcli.Field{
+			{
+				ID:        "username",
+				Type:      opcli.FieldTypeString,
+				Label:     "username",
+				Value:     "qux",
+				Reference: "op://Personal/Foo API Key/username",
+			},
+			{
+				ID:        "credential",
+				Type:      opcli.FieldTypeConcealed,
+				Label:     "credential",
+				Value:     "wat",
+				Reference: "op://Personal/Foo API Key/credential",
+			},
+			{
+				ID:        "hostname",
+				Type:      opcli.FieldTypeString,
+				Label:     "hostname",
+				Value:     "foo.com",
+				Reference: "op://Personal/Foo API Key/hostname",
+			},
+		},
+	}
+	assert.Equal(t, opcli.Item{
+		ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title:    "Foo API Key",
+		Version:  3,
+		Category: opcli.CategoryAPICredential,
+		Fields: []opcli.Field{
+			{
+				ID:    "hostname",
+				Type:  opcli.FieldTypeString,
+				Label: "hostname",
+				Value: "foo.com",
+			},
+		},
+	}, metadata(item, DefaultFields))
+}
+
+func TestMetadataSections(t *testing.T) {
+	acme := opcli.Section{ID: "Section_1", Label: "Acme"}
+	item := &opcli.Item{
+		ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title:    "GitHub",
+		Sections: []opcli.Section{acme},
+		Fields: []opcli.Field{
+			{ID: "username", Type: opcli.FieldTypeString, Label: "username", Value: "qux"},
+			{ID: "path", Type: opcli.FieldTypeString, Label: "path", Value: "acme/app.git"},
+			{ID: "h2xm", Section: acme, Type: opcli.FieldTypeString, Label: "hostname", Value: "github.com"},
+			{ID: "o5ry", Section: acme, Type: opcli.FieldTypeConcealed, Label: "credential", Value: "wat"},
+		},
+	}
+	m := metadata(item, DefaultFields)
+	assert.Equal(t, opcli.Item{
+		ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title:    "GitHub",
+		Sections: []opcli.Section{acme},
+		Fields: []opcli.Field{
+			{ID: "h2xm", Section: acme, Type: opcli.FieldTypeString, Label: "hostname", Value: "github.com"},
+			{ID: "path", Type: opcli.FieldTypeString, Label: "path", Value: "acme/app.git"},
+		},
+	}, m)
+	assert.True(t, (&Attributes{Host: "github.com", Path: "acme/app.git"}).Match(credentials(&m, DefaultFields)[0]))
+}
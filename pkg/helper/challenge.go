@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Challenge is an authentication challenge of a WWW-Authenticate header.
+// See https://www.rfc-editor.org/rfc/rfc9110#section-11.6.1 for more details.
+type Challenge struct {
+	// Scheme is the authentication scheme in lower case (e.g., basic or bearer).
+	Scheme string
+
+	// Params maps the lower-case names of the auth parameters to their values.
+	// A token68 (e.g., of the Negotiate scheme) is stored under an empty name.
+	Params map[string]string
+}
+
+// ParseChallenges parses the challenges of a WWW-Authenticate header value.
+// Malformed parts are skipped.
+func ParseChallenges(header string) []Challenge {
+	var cs []Challenge
+	p := &challengeParser{s: header}
+	for {
+		p.skip(" \t,")
+		if p.done() {
+			return cs
+		}
+		tok := p.token()
+		if tok == "" {
+			// not a token; skip the character
+			p.i++
+			continue
+		}
+		// a token followed by = is a parameter of the current challenge
+		mark := p.i
+		p.skip(" \t")
+		if len(cs) > 0 && p.peek('=') {
+			p.i++
+			p.skip(" \t")
+			cs[len(cs)-1].Params[strings.ToLower(tok)] = p.value()
+			continue
+		}
+		p.i = mark
+		c := Challenge{Scheme: strings.ToLower(tok), Params: map[string]string{}}
+		p.skip(" \t")
+		if t, ok := p.token68(); ok {
+			c.Params[""] = t
+		}
+		cs = append(cs, c)
+	}
+}
+
+// Realm returns the realm of the challenge. For challenges without a realm,
+// such as the Bearer challenge of Azure DevOps, it's the authorization_uri naming the tenant.
+func (c Challenge) Realm() string {
+	if r, ok := c.Params["realm"]; ok {
+		return r
+	}
+	return c.Params["authorization_uri"]
+}
+
+func (c Challenge) String() string {
+	if r := c.Realm(); r != "" {
+		return fmt.Sprintf("%s realm=%q", c.Scheme, r)
+	}
+	return c.Scheme
+}
+
+// Challenges returns the challenges of all WWW-Authenticate headers git received, in order.
+// Git sends them only with a get, and only since version 2.41.
+func (a *Attributes) Challenges() []Challenge {
+	var cs []Challenge
+	for _, h := range a.WWWAuth {
+		cs = append(cs, ParseChallenges(h)...)
+	}
+	return cs
+}
+
+// challengeMismatch explains why the item answers none of the challenges, or returns an empty string when it does.
+//
+// Items declare the schemes and realms they answer with auth:<scheme> and realm:<realm> tags.
+// Items without these tags answer any challenge, and any item matches when git sends no challenges.
+func (a *Attributes) challengeMismatch(item *opcli.Item) string {
+	schemes, realms := challengeValues(item)
+	if len(schemes) == 0 && len(realms) == 0 {
+		return ""
+	}
+	cs := a.Challenges()
+	if len(cs) == 0 {
+		return ""
+	}
+	var offered []string
+	for _, c := range cs {
+		if (len(schemes) == 0 || slices.Contains(schemes, c.Scheme)) && (len(realms) == 0 || slices.Contains(realms, c.Realm())) {
+			return ""
+		}
+		offered = append(offered, c.String())
+	}
+	var declared []string
+	for _, s := range schemes {
+		declared = append(declared, "auth:"+s)
+	}
+	for _, r := range realms {
+		declared = append(declared, "realm:"+r)
+	}
+	return fmt.Sprintf("challenges %s not answered by %s", strings.Join(offered, ", "), strings.Join(declared, ","))
+}
+
+// challengeValues returns the lower-case schemes and the realms the item declares in its tags.
+func challengeValues(item *opcli.Item) (schemes []string, realms []string) {
+	for _, t := range item.Tags {
+		if v, ok := strings.CutPrefix(t, "auth:"); ok && v != "" {
+			schemes = append(schemes, strings.ToLower(v))
+		}
+		if v, ok := strings.CutPrefix(t, "realm:"); ok && v != "" {
+			realms = append(realms, v)
+		}
+	}
+	return schemes, realms
+}
+
+// challengeParser reads the parts of a WWW-Authenticate header value.
+type challengeParser struct {
+	s string
+	i int
+}
+
+func (p *challengeParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *challengeParser) peek(c byte) bool {
+	return !p.done() && p.s[p.i] == c
+}
+
+// skip advances past any of the characters.
+func (p *challengeParser) skip(chars string) {
+	for !p.done() && strings.IndexByte(chars, p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// token reads a token, or returns an empty string when there's none.
+func (p *challengeParser) token() string {
+	start := p.i
+	for !p.done() && isTokenChar(p.s[p.i]) {
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// token68 reads a token68 when it makes up the rest of the challenge.
+func (p *challengeParser) token68() (string, bool) {
+	start := p.i
+	for !p.done() && (isAlphaNum(p.s[p.i]) || strings.IndexByte("-._~+/", p.s[p.i]) >= 0) {
+		p.i++
+	}
+	if p.i == start {
+		return "", false
+	}
+	p.skip("=")
+	end := p.i
+	p.skip(" \t")
+	if !p.done() && !p.peek(',') {
+		p.i = start
+		return "", false
+	}
+	return p.s[start:end], true
+}
+
+// value reads a quoted string, unescaping it, or an unquoted value.
+// Unquoted values extend to the next comma or space, since servers like Azure DevOps don't quote URLs.
+func (p *challengeParser) value() string {
+	if !p.peek('"') {
+		start := p.i
+		for !p.done() && strings.IndexByte(" \t,", p.s[p.i]) < 0 {
+			p.i++
+		}
+		return p.s[start:p.i]
+	}
+	p.i++
+	var b strings.Builder
+	for !p.done() {
+		c := p.s[p.i]
+		p.i++
+		switch {
+		case c == '"':
+			return b.String()
+		case c == '\\' && !p.done():
+			b.WriteByte(p.s[p.i])
+			p.i++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func isAlphaNum(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// isTokenChar reports whether c may appear in a token (see RFC 9110, section 5.6.2).
+func isTokenChar(c byte) bool {
+	return isAlphaNum(c) || strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,170 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallenges(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect []Challenge
+	}{
+		{
+			name:  "Blank",
+			value: "",
+		},
+		{
+			name:  "Basic",
+			value: `Basic realm="GitHub"`,
+			expect: []Challenge{
+				{Scheme: "basic", Params: map[string]string{"realm": "GitHub"}},
+			},
+		},
+		{
+			name:  "SchemeOnly",
+			value: "TFS-Federated",
+			expect: []Challenge{
+				{Scheme: "tfs-federated", Params: map[string]string{}},
+			},
+		},
+		{
+			name:  "Params",
+			value: `Bearer realm=example, error="invalid_token", error_description="The token \"foo\" expired"`,
+			expect: []Challenge{
+				{Scheme: "bearer", Params: map[string]string{
+					"realm":             "example",
+					"error":             "invalid_token",
+					"error_description": `The token "foo" expired`,
+				}},
+			},
+		},
+		{
+			name:  "Multiple",
+			value: `Bearer authorization_uri=https://login.microsoftonline.com/contoso, Basic realm="https://tfsprodcus3.visualstudio.com/", TFS-Federated`,
+			expect: []Challenge{
+				{Scheme: "bearer", Params: map[string]string{"authorization_uri": "https://login.microsoftonline.com/contoso"}},
+				{Scheme: "basic", Params: map[string]string{"realm": "https://tfsprodcus3.visualstudio.com/"}},
+				{Scheme: "tfs-federated", Params: map[string]string{}},
+			},
+		},
+		{
+			name:  "Token68",
+			value: "Negotiate YIIBhwYGKwYBBQUC==, Basic realm=foo",
+			expect: []Challenge{
+				{Scheme: "negotiate", Params: map[string]string{"": "YIIBhwYGKwYBBQUC=="}},
+				{Scheme: "basic", Params: map[string]string{"realm": "foo"}},
+			},
+		},
+		{
+			name:  "Malformed",
+			value: `, "foo" Basic realm="unterminated`,
+			expect: []Challenge{
+				{Scheme: "foo", Params: map[string]string{}},
+				{Scheme: "basic", Params: map[string]string{"realm": "unterminated"}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, ParseChallenges(test.value))
+		})
+	}
+}
+
+func TestChallengeRealm(t *testing.T) {
+	assert.Equal(t, "foo", Challenge{Params: map[string]string{"realm": "foo", "authorization_uri": "bar"}}.Realm())
+	assert.Equal(t, "bar", Challenge{Params: map[string]string{"authorization_uri": "bar"}}.Realm())
+	assert.Equal(t, "", Challenge{}.Realm())
+}
+
+func TestAttributesChallengeMismatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		wwwauth []string
+		tags    []string
+		expect  string
+	}{
+		{
+			name:    "Undeclared",
+			wwwauth: []string{`Bearer realm="foo"`},
+		},
+		{
+			name: "NoChallenges",
+			tags: []string{"auth:bearer"},
+		},
+		{
+			name:    "Scheme",
+			wwwauth: []string{`Bearer realm="foo"`, `Basic realm="foo"`},
+			tags:    []string{"auth:Basic"},
+		},
+		{
+			name:    "SchemeMismatch",
+			wwwauth: []string{`Basic realm="foo"`},
+			tags:    []string{"auth:bearer"},
+			expect:  `challenges basic realm="foo" not answered by auth:bearer`,
+		},
+		{
+			name:    "Realm",
+			wwwauth: []string{`Bearer authorization_uri=https://login.example.com/foo`},
+			tags:    []string{"auth:bearer", "realm:https://login.example.com/foo"},
+		},
+		{
+			name:    "RealmMismatch",
+			wwwauth: []string{`Bearer authorization_uri=https://login.example.com/foo, Basic realm="bar"`},
+			tags:    []string{"auth:bearer", "realm:bar"},
+			expect:  `challenges bearer realm="https://login.example.com/foo", basic realm="bar" not answered by auth:bearer,realm:bar`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attr := &Attributes{Protocol: "https", Host: "foo.com", WWWAuth: test.wwwauth}
+			item := &opcli.Item{Tags: append([]string{"host:foo.com"}, test.tags...)}
+			assert.Equal(t, test.expect, attr.mismatch(item, DefaultFields))
+		})
+	}
+}
+
+func TestRunGetChallenges(t *testing.T) {
+	tests := []struct {
+		name     string
+		wwwauth  []string
+		username string
+		password string
+	}{
+		{
+			name:     "Basic",
+			wwwauth:  []string{`Basic realm="https://tfsprodcus3.visualstudio.com/"`},
+			username: "pat",
+			password: "pat_secret",
+		},
+		{
+			name:     "BearerRealm",
+			wwwauth:  []string{`Bearer authorization_uri=https://login.microsoftonline.com/fabrikam`},
+			username: "fabrikam",
+			password: "fabrikam_token",
+		},
+		{
+			name:    "UnknownRealm",
+			wwwauth: []string{`Bearer authorization_uri=https://login.microsoftonline.com/northwind`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_challenge")
+			h := &Helper{Op: opcli.CLI{Path: op}}
+			res, err := h.Run(Get, &Attributes{Protocol: "https", Host: "dev.azure.com", WWWAuth: test.wwwauth})
+			assert.NoError(t, err)
+			if test.password == "" {
+				assert.Nil(t, res)
+			} else {
+				assert.Equal(t, test.username, res.Username)
+				assert.Equal(t, test.password, res.Password)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package helper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+var (
+	// ErrNoTerminal is returned by a Chooser when it is unable to prompt the user.
+	ErrNoTerminal = errors.New("no terminal available")
+
+	// ErrCanceled is returned by a Chooser when the user cancels the selection.
+	ErrCanceled = errors.New("selection canceled")
+)
+
+// Chooser picks one of several items matching the attributes and returns its index.
+// The fields map the credential attributes to item fields for describing the items (see Helper.Fields).
+type Chooser func(attr *Attributes, items []*opcli.Item, fields Fields) (int, error)
+
+// TerminalChooser prompts the user to pick an item on the controlling terminal.
+//
+// Since git talks to the helper over stdin and stdout, the prompt uses /dev/tty directly.
+// It returns ErrNoTerminal when there is no terminal or git prompts are disabled with GIT_TERMINAL_PROMPT=0.
+func TerminalChooser(attr *Attributes, items []*opcli.Item, fields Fields) (int, error) {
+	if os.Getenv("GIT_TERMINAL_PROMPT") == "0" {
+		return 0, ErrNoTerminal
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return 0, ErrNoTerminal
+	}
+	defer tty.Close()
+	return prompt(tty, tty, attr, items, fields)
+}
+
+// prompt lists the items and reads the choice of the user.
+func prompt(r io.Reader, w io.Writer, attr *Attributes, items []*opcli.Item, fields Fields) (int, error) {
+	fmt.Fprintf(w, "Multiple 1Password items match %s:\n", attr.RemoteURL())
+	for i, item := range items {
+		fmt.Fprintf(w, "  %d) %s\n", i+1, describe(item, fields))
+	}
+	s := bufio.NewScanner(r)
+	for attempt := 0; attempt < 3; attempt++ {
+		fmt.Fprintf(w, "Choose an item [1-%d] or press enter to cancel: ", len(items))
+		if !s.Scan() {
+			fmt.Fprintln(w)
+			return 0, ErrCanceled
+		}
+		v := strings.TrimSpace(s.Text())
+		if v == "" || v == "q" {
+			return 0, ErrCanceled
+		}
+		if i, err := strconv.Atoi(v); err == nil && i >= 1 && i <= len(items) {
+			return i - 1, nil
+		}
+		fmt.Fprintf(w, "Invalid choice: %q\n", v)
+	}
+	return 0, ErrCanceled
+}
+
+// describe returns a one-line description of the item without any secrets, reading the username according to the mapping.
+func describe(item *opcli.Item, fields Fields) string {
+	var b strings.Builder
+	b.WriteString(item.Title)
+	if s := scope(item); s != nil && s.Label != "" {
+		fmt.Fprintf(&b, " / %s", s.Label)
+	}
+	fmt.Fprintf(&b, " (%s)", item.Vault.Name)
+	if f := DefaultFields.Merge(fields).username(item); f != nil {
+		fmt.Fprintf(&b, ", username %s", f.Value)
+	}
+	if !item.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, ", updated %s", item.UpdatedAt.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+// Fallback selects an item when several match and the user can't be asked.
+type Fallback string
+
+const (
+	// FallbackFirst selects the first matching item (default).
+	FallbackFirst Fallback = "first"
+
+	// FallbackNewest selects the most recently updated matching item.
+	FallbackNewest Fallback = "newest"
+
+	// FallbackNone selects no item, letting git fall back to other helpers or prompts.
+	FallbackNone Fallback = "none"
+)
+
+// ParseFallback parses the fallback rule.
+func ParseFallback(s string) (Fallback, error) {
+	switch f := Fallback(s); f {
+	case "", FallbackFirst, FallbackNewest, FallbackNone:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown fallback: %q (expected first, newest, or none)", s)
+	}
+}
+
+// name returns the name of the fallback rule, including the default.
+func (f Fallback) name() string {
+	if f == "" {
+		return string(FallbackFirst)
+	}
+	return string(f)
+}
+
+// apply selects an item according to the fallback rule.
+func (f Fallback) apply(items []*opcli.Item) *opcli.Item {
+	switch f {
+	case FallbackNone:
+		return nil
+	case FallbackNewest:
+		sorted := append([]*opcli.Item(nil), items...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].UpdatedAt.After(sorted[j].UpdatedAt) })
+		return sorted[0]
+	default:
+		return items[0]
+	}
+}
+
+// choose selects one of the items matching the attributes and explains why.
+//
+// A single match is used as is. Otherwise, the remembered preference is used if it still matches,
+// then the user is asked with the Chooser, and when that's not possible the Fallback rule applies.
+func (h *Helper) choose(attr *Attributes, items []*opcli.Item) (*opcli.Item, string, error) {
+	switch len(items) {
+	case 0:
+		return nil, "no match", nil
+	case 1:
+		return items[0], "single match", nil
+	}
+	key := attr.RemoteURL()
+	if h.Preferences != nil {
+		if id, ok := h.Preferences.Get(key); ok {
+			for _, item := range items {
+				if credentialID(item) == id {
+					return item, "remembered choice", nil
+				}
+			}
+		}
+	}
+	if h.Chooser != nil {
+		i, err := h.Chooser(attr, items, h.fields())
+		switch {
+		case err == nil:
+			if h.Preferences != nil {
+				_ = h.Preferences.Set(key, credentialID(items[i]))
+			}
+			return items[i], "chosen by the user", nil
+		case !errors.Is(err, ErrNoTerminal):
+			return nil, "", err
+		}
+	}
+	return h.Fallback.apply(items), fmt.Sprintf("%s of %d matches", h.Fallback.name(), len(items)), nil
+}
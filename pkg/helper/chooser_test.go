@@ -0,0 +1,214 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+var chooserItems = []*opcli.Item{
+	{
+		ID:        "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title:     "Foo API Key",
+		Vault:     opcli.Vault{Name: "Personal"},
+		UpdatedAt: time.Date(2022, time.April, 20, 9, 41, 0, 0, time.UTC),
+		Fields: []opcli.Field{
+			{ID: "username", Label: "username", Value: "qux"},
+			{ID: "credential", Label: "credential", Type: opcli.FieldTypeConcealed, Value: "wat"},
+		},
+	},
+	{
+		ID:        "q5ajmvdxlpxtk3wr5ga3ohjn7y",
+		Title:     "Bar API Key",
+		Vault:     opcli.Vault{Name: "Team"},
+		UpdatedAt: time.Date(2022, time.May, 20, 9, 41, 0, 0, time.UTC),
+	},
+}
+
+func TestPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect int
+		err    error
+		output string
+	}{
+		{
+			name:   "Choice",
+			input:  "2\n",
+			expect: 1,
+			output: "Multiple 1Password items match https://foo.com/:\n  1) Foo API Key (Personal), username qux, updated 2022-04-20\n  2) Bar API Key (Team), updated 2022-05-20\nChoose an item [1-2] or press enter to cancel: ",
+		},
+		{
+			name:   "Retry",
+			input:  "foo\n3\n1\n",
+			expect: 0,
+		},
+		{
+			name:  "Cancel",
+			input: "\n",
+			err:   ErrCanceled,
+		},
+		{
+			name:  "EOF",
+			input: "",
+			err:   ErrCanceled,
+		},
+		{
+			name:  "TooManyAttempts",
+			input: "0\n0\n0\n1\n",
+			err:   ErrCanceled,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			i, err := prompt(bytes.NewBufferString(test.input), &out, &Attributes{Protocol: "https", Host: "foo.com"}, chooserItems, DefaultFields)
+			if test.err == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, i)
+			} else {
+				assert.ErrorIs(t, err, test.err)
+			}
+			if test.output != "" {
+				assert.Equal(t, test.output, out.String())
+			}
+			assert.NotContains(t, out.String(), "wat")
+		})
+	}
+}
+
+func TestDescribeFields(t *testing.T) {
+	item := &opcli.Item{
+		Title: "GitHub",
+		Vault: opcli.Vault{Name: "Personal"},
+		Fields: []opcli.Field{
+			{ID: "username", Label: "username", Value: "default"},
+			{ID: "h2xm", Label: "login", Value: "octocat"},
+		},
+	}
+	assert.Equal(t, "GitHub (Personal), username default", describe(item, DefaultFields))
+	assert.Equal(t, "GitHub (Personal), username octocat", describe(item, Fields{Username: []string{"login"}}))
+}
+
+func TestTerminalChooserDisabled(t *testing.T) {
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	_, err := TerminalChooser(&Attributes{}, chooserItems, DefaultFields)
+	assert.ErrorIs(t, err, ErrNoTerminal)
+}
+
+func TestParseFallback(t *testing.T) {
+	for _, v := range []string{"", "first", "newest", "none"} {
+		f, err := ParseFallback(v)
+		assert.NoError(t, err)
+		assert.Equal(t, Fallback(v), f)
+	}
+	_, err := ParseFallback("foo")
+	assert.EqualError(t, err, `unknown fallback: "foo" (expected first, newest, or none)`)
+}
+
+func TestHelperChoose(t *testing.T) {
+	attr := &Attributes{Protocol: "https", Host: "foo.com"}
+	tests := []struct {
+		name     string
+		items    []*opcli.Item
+		chooser  Chooser
+		fallback Fallback
+		pref     string
+		expect   *opcli.Item
+		reason   string
+		err      error
+		saved    string
+	}{
+		{
+			name:   "None",
+			expect: nil,
+			reason: "no match",
+		},
+		{
+			name:    "Single",
+			items:   chooserItems[1:],
+			chooser: func(*Attributes, []*opcli.Item, Fields) (int, error) { return 0, errors.New("unexpected") },
+			expect:  chooserItems[1],
+			reason:  "single match",
+		},
+		{
+			name:    "Chooser",
+			items:   chooserItems,
+			chooser: func(*Attributes, []*opcli.Item, Fields) (int, error) { return 1, nil },
+			expect:  chooserItems[1],
+			reason:  "chosen by the user",
+			saved:   "q5ajmvdxlpxtk3wr5ga3ohjn7y",
+		},
+		{
+			name:    "ChooserCanceled",
+			items:   chooserItems,
+			chooser: func(*Attributes, []*opcli.Item, Fields) (int, error) { return 0, ErrCanceled },
+			err:     ErrCanceled,
+		},
+		{
+			name:    "Preference",
+			items:   chooserItems,
+			chooser: func(*Attributes, []*opcli.Item, Fields) (int, error) { return 0, errors.New("unexpected") },
+			pref:    "q5ajmvdxlpxtk3wr5ga3ohjn7y",
+			expect:  chooserItems[1],
+			reason:  "remembered choice",
+			saved:   "q5ajmvdxlpxtk3wr5ga3ohjn7y",
+		},
+		{
+			name:    "PreferenceStale",
+			items:   chooserItems,
+			chooser: func(*Attributes, []*opcli.Item, Fields) (int, error) { return 0, nil },
+			pref:    "utfq63h5szb3jeembehuoioc4f",
+			expect:  chooserItems[0],
+			reason:  "chosen by the user",
+			saved:   "kpbhk2zfw6m4pgdwylbbpmkcke",
+		},
+		{
+			name:    "FallbackFirst",
+			items:   chooserItems,
+			chooser: func(*Attributes, []*opcli.Item, Fields) (int, error) { return 0, ErrNoTerminal },
+			expect:  chooserItems[0],
+			reason:  "first of 2 matches",
+		},
+		{
+			name:     "FallbackNewest",
+			items:    chooserItems,
+			fallback: FallbackNewest,
+			expect:   chooserItems[1],
+			reason:   "newest of 2 matches",
+		},
+		{
+			name:     "FallbackNone",
+			items:    chooserItems,
+			chooser:  func(*Attributes, []*opcli.Item, Fields) (int, error) { return 0, ErrNoTerminal },
+			fallback: FallbackNone,
+			expect:   nil,
+			reason:   "none of 2 matches",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prefs := &Preferences{Path: filepath.Join(t.TempDir(), "preferences.json")}
+			if test.pref != "" {
+				assert.NoError(t, prefs.Set("https://foo.com/", test.pref))
+			}
+			h := &Helper{Chooser: test.chooser, Fallback: test.fallback, Preferences: prefs}
+			item, reason, err := h.choose(attr, test.items)
+			if test.err == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, item)
+				assert.Equal(t, test.reason, reason)
+			} else {
+				assert.ErrorIs(t, err, test.err)
+			}
+			saved, _ := prefs.Get("https://foo.com/")
+			assert.Equal(t, test.saved, saved)
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gbernady/git-credential-op/pkg/forge"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Config represents the helper configuration file.
+//
+// Example:
+//
+//	account: my.1password.com
+//	vault: Personal
+//	categories: [API Credential]
+//	tag: git-credential
+//	locations:
+//	  - account: work.1password.com
+//	    vault: Team
+//	  - vault: Personal
+//	    primary: true
+//	fields:
+//	  username: [username, purpose:username]
+//	  password: [credential, purpose:password, token]
+//	  expiry: [expires]
+//	expiry_warning: 336h
+//	validate: true
+//	exclusive: true
+//	recipe: letters,digits,symbols,32
+//	fallback: newest
+//	remember: true
+//	cache:
+//	  ttl: 10m
+//	references:
+//	  - host: github.com
+//	    path: work-org/*
+//	    username: x-access-token
+//	    password: op://Work/GitHub/token
+//	hosts:
+//	  - host: "*.corp.example.com"
+//	    account: work.1password.com
+//	    vault: Engineering
+//	    categories: [Login]
+//	    fields:
+//	      password: [Git/token]
+//	  - host: gitea.corp.example.com
+//	    recipe: letters,digits,40
+//	    provider: gitea
+type Config struct {
+	// Account specifies the default account to use.
+	Account string `yaml:"account"`
+
+	// Vault specifies the default vault to use.
+	Vault string `yaml:"vault"`
+
+	// Categories specifies the default item categories searched for credentials.
+	Categories []string `yaml:"categories"`
+
+	// Tag restricts the search to items with the tag.
+	Tag string `yaml:"tag"`
+
+	// Item specifies the item (name, ID, or sharing link) serving credentials, bypassing discovery.
+	Item string `yaml:"item"`
+
+	// Locations specifies the accounts and vaults searched for credentials, in order.
+	Locations []LocationConfig `yaml:"locations"`
+
+	// Fields maps the credential attributes to item fields.
+	Fields FieldsConfig `yaml:"fields"`
+
+	// Recipe specifies how passwords are generated, e.g. letters,digits,32 (see opcli.ParseRecipe).
+	Recipe string `yaml:"recipe"`
+
+	// Provider names the git hosting service of the remotes: github, gitlab, or gitea (see forge.For).
+	Provider string `yaml:"provider"`
+
+	// Validate enables checking credentials against the API of the provider before they are returned.
+	Validate bool `yaml:"validate"`
+
+	// Exclusive stops git from asking other helpers and the user when no credential is found.
+	Exclusive bool `yaml:"exclusive"`
+
+	// ExpiryWarning specifies how long before credentials expire to warn about them; 0 disables the warnings.
+	ExpiryWarning *time.Duration `yaml:"expiry_warning"`
+
+	// Fallback selects one of several matching items when the user can't be asked (first, newest, or none).
+	Fallback string `yaml:"fallback"`
+
+	// Remember enables remembering the items chosen when several match.
+	Remember bool `yaml:"remember"`
+
+	// Cache configures the item metadata cache.
+	Cache CacheConfig `yaml:"cache"`
+
+	// References map remotes to explicit secret references.
+	References []ReferenceConfig `yaml:"references"`
+
+	// Hosts override the defaults for matching remotes.
+	Hosts []HostConfig `yaml:"hosts"`
+}
+
+// LocationConfig is an account and vault searched for credentials (see Location).
+type LocationConfig struct {
+	Account string `yaml:"account"`
+	Vault   string `yaml:"vault"`
+	Primary bool   `yaml:"primary"`
+}
+
+// FieldsConfig maps the credential attributes to item fields (see Fields).
+type FieldsConfig struct {
+	Username []string `yaml:"username"`
+	Password []string `yaml:"password"`
+	Hostname []string `yaml:"hostname"`
+	Path     []string `yaml:"path"`
+	Expiry   []string `yaml:"expiry"`
+}
+
+// CacheConfig configures the item metadata cache.
+type CacheConfig struct {
+	// TTL specifies how long item metadata is cached; caching is disabled when zero.
+	TTL time.Duration `yaml:"ttl"`
+
+	// Path overrides the default location of the cache file.
+	Path string `yaml:"path"`
+}
+
+// ReferenceConfig maps remotes to explicit secret references (see Reference).
+type ReferenceConfig struct {
+	Host     string `yaml:"host"`
+	Path     string `yaml:"path"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// HostConfig overrides the defaults for remotes matching the host and path patterns (see Rule).
+type HostConfig struct {
+	Host       string           `yaml:"host"`
+	Path       string           `yaml:"path"`
+	Account    string           `yaml:"account"`
+	Vault      string           `yaml:"vault"`
+	Categories []string         `yaml:"categories"`
+	Tag        string           `yaml:"tag"`
+	Item       string           `yaml:"item"`
+	Locations  []LocationConfig `yaml:"locations"`
+	Fields     FieldsConfig     `yaml:"fields"`
+	Recipe     string           `yaml:"recipe"`
+	Provider   string           `yaml:"provider"`
+	Exclusive  bool             `yaml:"exclusive"`
+}
+
+// DefaultConfigPath returns the default location of the configuration file.
+//
+// It is $GIT_CREDENTIAL_OP_CONFIG when set, or git-credential-op/config.yaml
+// within $XDG_CONFIG_HOME (defaults to $HOME/.config) otherwise.
+func DefaultConfigPath() (string, error) {
+	if p := os.Getenv("GIT_CREDENTIAL_OP_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "git-credential-op", "config.yaml"), nil
+}
+
+// LoadConfig reads and validates the configuration file.
+// A missing file results in an error satisfying errors.Is(err, fs.ErrNotExist).
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseConfig(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ParseConfig parses and validates the configuration.
+func ParseConfig(r io.Reader) (*Config, error) {
+	c := &Config{}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	if _, err := parseCategories(c.Categories); err != nil {
+		return fmt.Errorf("categories: %w", err)
+	}
+	if err := validateLocations("locations", c.Locations); err != nil {
+		return err
+	}
+	if err := c.Fields.validate("fields"); err != nil {
+		return err
+	}
+	if _, err := opcli.ParseRecipe(c.Recipe); err != nil {
+		return fmt.Errorf("recipe: %w", err)
+	}
+	if err := validateProvider(c.Provider); err != nil {
+		return fmt.Errorf("provider: %w", err)
+	}
+	if _, err := ParseFallback(c.Fallback); err != nil {
+		return fmt.Errorf("fallback: %w", err)
+	}
+	if c.ExpiryWarning != nil && *c.ExpiryWarning < 0 {
+		return fmt.Errorf("expiry_warning: must not be negative")
+	}
+	if c.Cache.TTL < 0 {
+		return fmt.Errorf("cache.ttl: must not be negative")
+	}
+	for i, r := range c.References {
+		if r.Host == "" {
+			return fmt.Errorf("references[%d].host: required", i)
+		}
+		if err := validatePatterns(r.Host, r.Path); err != nil {
+			return fmt.Errorf("references[%d]: %w", i, err)
+		}
+		if !strings.HasPrefix(r.Password, "op://") {
+			return fmt.Errorf("references[%d].password: must be a secret reference (op://vault/item/field)", i)
+		}
+	}
+	for i, h := range c.Hosts {
+		if h.Host == "" {
+			return fmt.Errorf("hosts[%d].host: required", i)
+		}
+		if err := validatePatterns(h.Host, h.Path); err != nil {
+			return fmt.Errorf("hosts[%d]: %w", i, err)
+		}
+		if _, err := parseCategories(h.Categories); err != nil {
+			return fmt.Errorf("hosts[%d].categories: %w", i, err)
+		}
+		if err := validateLocations(fmt.Sprintf("hosts[%d].locations", i), h.Locations); err != nil {
+			return err
+		}
+		if err := h.Fields.validate(fmt.Sprintf("hosts[%d].fields", i)); err != nil {
+			return err
+		}
+		if _, err := opcli.ParseRecipe(h.Recipe); err != nil {
+			return fmt.Errorf("hosts[%d].recipe: %w", i, err)
+		}
+		if err := validateProvider(h.Provider); err != nil {
+			return fmt.Errorf("hosts[%d].provider: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Apply configures the helper with values set in the configuration.
+func (c *Config) Apply(h *Helper) error {
+	categories, err := parseCategories(c.Categories)
+	if err != nil {
+		return err
+	}
+	h.Op.Account = c.Account
+	h.Vault = c.Vault
+	h.Categories = categories
+	h.Tag = c.Tag
+	h.Item = c.Item
+	h.Locations = locations(c.Locations)
+	h.Fields = Fields(c.Fields)
+	if h.Recipe, err = opcli.ParseRecipe(c.Recipe); err != nil {
+		return err
+	}
+	if h.Fallback, err = ParseFallback(c.Fallback); err != nil {
+		return err
+	}
+	h.Provider = c.Provider
+	h.Exclusive = c.Exclusive
+	if c.Validate {
+		path, err := DefaultValidationPath()
+		if err != nil {
+			return err
+		}
+		h.Validator = &Validator{Path: path, TTL: DefaultValidationTTL}
+	}
+	if c.ExpiryWarning != nil {
+		h.ExpiryWarning = *c.ExpiryWarning
+	}
+	if c.Remember {
+		path, err := DefaultPreferencesPath()
+		if err != nil {
+			return err
+		}
+		h.Preferences = &Preferences{Path: path}
+	}
+	if c.Cache.TTL > 0 {
+		path := c.Cache.Path
+		if path == "" {
+			if path, err = DefaultCachePath(); err != nil {
+				return err
+			}
+		}
+		h.Cache = &Cache{Path: path, TTL: c.Cache.TTL}
+	}
+	for _, r := range c.References {
+		h.References = append(h.References, Reference(r))
+	}
+	for _, v := range c.Hosts {
+		categories, err := parseCategories(v.Categories)
+		if err != nil {
+			return err
+		}
+		recipe, err := opcli.ParseRecipe(v.Recipe)
+		if err != nil {
+			return err
+		}
+		h.Rules = append(h.Rules, Rule{
+			Host: v.Host,
+			Path: v.Path,
+			Settings: Settings{
+				Account:    v.Account,
+				Vault:      v.Vault,
+				Categories: categories,
+				Tag:        v.Tag,
+				Item:       v.Item,
+				Locations:  locations(v.Locations),
+				Fields:     Fields(v.Fields),
+				Recipe:     recipe,
+				Provider:   v.Provider,
+				Exclusive:  v.Exclusive,
+			},
+		})
+	}
+	return nil
+}
+
+func validateProvider(name string) error {
+	if name != "" && !slices.Contains(forge.Providers, strings.ToLower(name)) {
+		return fmt.Errorf("unknown provider %q: expected one of %s", name, strings.Join(forge.Providers, ", "))
+	}
+	return nil
+}
+
+func validateLocations(field string, values []LocationConfig) error {
+	primary := 0
+	for i, l := range values {
+		if l.Vault == "" {
+			return fmt.Errorf("%s[%d].vault: required", field, i)
+		}
+		if l.Primary {
+			primary++
+		}
+	}
+	if primary > 1 {
+		return fmt.Errorf("%s: only one location can be primary", field)
+	}
+	return nil
+}
+
+func (c FieldsConfig) validate(field string) error {
+	for _, v := range []struct {
+		name      string
+		selectors []string
+	}{
+		{"username", c.Username},
+		{"password", c.Password},
+		{"hostname", c.Hostname},
+		{"path", c.Path},
+		{"expiry", c.Expiry},
+	} {
+		for i, s := range v.selectors {
+			if err := validateSelector(s); err != nil {
+				return fmt.Errorf("%s.%s[%d]: %w", field, v.name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func locations(values []LocationConfig) []Location {
+	var locations []Location
+	for _, l := range values {
+		locations = append(locations, Location(l))
+	}
+	return locations
+}
+
+func parseCategories(values []string) ([]opcli.Category, error) {
+	var categories []opcli.Category
+	for _, v := range values {
+		c, err := opcli.ParseCategory(v)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, nil
+}
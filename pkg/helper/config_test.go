@@ -0,0 +1,293 @@
+package helper
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect *Config
+		err    string
+	}{
+		{
+			name:   "Blank",
+			value:  "",
+			expect: &Config{},
+		},
+		{
+			name: "All",
+			value: `
+account: my.1password.com
+vault: Personal
+categories: [API Credential, LOGIN]
+fields:
+  password: [token, purpose:password]
+cache:
+  ttl: 10m
+  path: /tmp/items.json
+references:
+  - host: github.com
+    path: work-org/*
+    username: x-access-token
+    password: op://Work/GitHub/token
+hosts:
+  - host: "*.corp.example.com"
+    account: work.1password.com
+    vault: Engineering
+    categories: [Login]
+    item: Corp Git
+    fields:
+      username: [Git/username]
+`,
+			expect: &Config{
+				Account:    "my.1password.com",
+				Vault:      "Personal",
+				Categories: []string{"API Credential", "LOGIN"},
+				Fields: FieldsConfig{
+					Password: []string{"token", "purpose:password"},
+				},
+				Cache: CacheConfig{
+					TTL:  10 * time.Minute,
+					Path: "/tmp/items.json",
+				},
+				References: []ReferenceConfig{
+					{
+						Host:     "github.com",
+						Path:     "work-org/*",
+						Username: "x-access-token",
+						Password: "op://Work/GitHub/token",
+					},
+				},
+				Hosts: []HostConfig{
+					{
+						Host:       "*.corp.example.com",
+						Account:    "work.1password.com",
+						Vault:      "Engineering",
+						Categories: []string{"Login"},
+						Item:       "Corp Git",
+						Fields: FieldsConfig{
+							Username: []string{"Git/username"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "UnknownField",
+			value: "account: foo\nvalut: bar\n",
+			err:   "yaml: unmarshal errors:\n  line 2: field valut not found in type helper.Config",
+		},
+		{
+			name:  "InvalidCategory",
+			value: "categories: [Foo]\n",
+			err:   `categories: unknown category: "Foo"`,
+		},
+		{
+			name:  "InvalidTTL",
+			value: "cache:\n  ttl: soon\n",
+			err:   "yaml: unmarshal errors:\n  line 2: cannot unmarshal !!str `soon` into time.Duration",
+		},
+		{
+			name:  "NegativeExpiryWarning",
+			value: "expiry_warning: -24h\n",
+			err:   "expiry_warning: must not be negative",
+		},
+		{
+			name:  "InvalidFieldExpiry",
+			value: "fields:\n  expiry: [purpose:expiry]\n",
+			err:   `fields.expiry[0]: invalid field "purpose:expiry": expected purpose:username or purpose:password`,
+		},
+		{
+			name:  "InvalidProvider",
+			value: "provider: bitbucket\n",
+			err:   `provider: unknown provider "bitbucket": expected one of github, gitlab, gitea`,
+		},
+		{
+			name:  "HostInvalidProvider",
+			value: "hosts:\n  - host: git.example.com\n    provider: gogs\n",
+			err:   `hosts[0].provider: unknown provider "gogs": expected one of github, gitlab, gitea`,
+		},
+		{
+			name:  "InvalidFallback",
+			value: "fallback: last\n",
+			err:   `fallback: unknown fallback: "last" (expected first, newest, or none)`,
+		},
+		{
+			name:  "LocationMissingVault",
+			value: "locations:\n  - account: foo\n",
+			err:   "locations[0].vault: required",
+		},
+		{
+			name:  "LocationMultiplePrimary",
+			value: "hosts:\n  - host: foo.com\n    locations:\n      - vault: foo\n        primary: true\n      - vault: bar\n        primary: true\n",
+			err:   "hosts[0].locations: only one location can be primary",
+		},
+		{
+			name:  "ReferenceMissingHost",
+			value: "references:\n  - password: op://Work/GitHub/token\n",
+			err:   "references[0].host: required",
+		},
+		{
+			name:  "ReferenceLiteralPassword",
+			value: "references:\n  - host: github.com\n    password: foo\n",
+			err:   "references[0].password: must be a secret reference (op://vault/item/field)",
+		},
+		{
+			name:  "HostInvalidPattern",
+			value: "hosts:\n  - host: github.com\n  - host: \"[foo\"\n",
+			err:   "hosts[1]: syntax error in pattern",
+		},
+		{
+			name:  "HostInvalidCategory",
+			value: "hosts:\n  - host: github.com\n    categories: [Foo]\n",
+			err:   `hosts[0].categories: unknown category: "Foo"`,
+		},
+		{
+			name:  "InvalidFieldPurpose",
+			value: "fields:\n  username: [username, purpose:notes]\n",
+			err:   `fields.username[1]: invalid field "purpose:notes": expected purpose:username or purpose:password`,
+		},
+		{
+			name:  "HostInvalidFieldSection",
+			value: "hosts:\n  - host: github.com\n    fields:\n      password: [/token]\n",
+			err:   `hosts[0].fields.password[0]: invalid field "/token": expected [section/]name`,
+		},
+		{
+			name:  "InvalidRecipe",
+			value: "recipe: letters,100\n",
+			err:   `recipe: invalid recipe "letters,100": expected character sets (letters, digits, symbols) and a length between 1 and 64`,
+		},
+		{
+			name:  "HostInvalidRecipe",
+			value: "hosts:\n  - host: gitea.example.com\n    recipe: \"40\"\n",
+			err:   `hosts[0].recipe: invalid recipe "40": expected at least one character set (letters, digits, symbols)`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := ParseConfig(bytes.NewBufferString(test.value))
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, c)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := LoadConfig(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("categories: [Foo]\n"), 0644))
+	_, err = LoadConfig(path)
+	assert.EqualError(t, err, "invalid config "+path+`: categories: unknown category: "Foo"`)
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	t.Setenv("GIT_CREDENTIAL_OP_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/foo")
+	t.Setenv("HOME", "/bar")
+	p, err := DefaultConfigPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/foo/git-credential-op/config.yaml", p)
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	p, err = DefaultConfigPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/bar/.config/git-credential-op/config.yaml", p)
+
+	t.Setenv("GIT_CREDENTIAL_OP_CONFIG", "/baz/config.yaml")
+	p, err = DefaultConfigPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/baz/config.yaml", p)
+}
+
+func TestConfigApply(t *testing.T) {
+	c := &Config{
+		Account:    "my.1password.com",
+		Vault:      "Personal",
+		Categories: []string{"API Credential", "LOGIN"},
+		Locations: []LocationConfig{
+			{Account: "work", Vault: "Team"},
+			{Vault: "Personal", Primary: true},
+		},
+		Fields: FieldsConfig{
+			Password: []string{"token"},
+		},
+		Cache: CacheConfig{
+			TTL:  10 * time.Minute,
+			Path: "/tmp/items.json",
+		},
+		References: []ReferenceConfig{
+			{
+				Host:     "github.com",
+				Username: "x-access-token",
+				Password: "op://Work/GitHub/token",
+			},
+		},
+		Hosts: []HostConfig{
+			{
+				Host:  "*.corp.example.com",
+				Vault: "Engineering",
+			},
+			{
+				Host:      "git.example.com",
+				Exclusive: true,
+			},
+		},
+	}
+	c.Fallback = "newest"
+	c.Provider = "gitlab"
+	c.Exclusive = true
+	warning := 14 * 24 * time.Hour
+	c.ExpiryWarning = &warning
+	h := &Helper{ExpiryWarning: DefaultExpiryWarning}
+	assert.NoError(t, c.Apply(h))
+	assert.Equal(t, &Helper{
+		Op:         opcli.CLI{Account: "my.1password.com"},
+		Vault:      "Personal",
+		Categories: []opcli.Category{opcli.CategoryAPICredential, opcli.CategoryLogin},
+		Locations: []Location{
+			{Account: "work", Vault: "Team"},
+			{Vault: "Personal", Primary: true},
+		},
+		Fields:        Fields{Password: []string{"token"}},
+		Fallback:      FallbackNewest,
+		ExpiryWarning: 14 * 24 * time.Hour,
+		Provider:      "gitlab",
+		Exclusive:     true,
+		Cache:         &Cache{Path: "/tmp/items.json", TTL: 10 * time.Minute},
+		References: []Reference{
+			{
+				Host:     "github.com",
+				Username: "x-access-token",
+				Password: "op://Work/GitHub/token",
+			},
+		},
+		Rules: []Rule{
+			{
+				Host:     "*.corp.example.com",
+				Settings: Settings{Vault: "Engineering"},
+			},
+			{
+				Host:     "git.example.com",
+				Settings: Settings{Exclusive: true},
+			},
+		},
+	}, h)
+}
@@ -0,0 +1,140 @@
+package helper
+
+import (
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Summary describes a credential without its secrets.
+type Summary struct {
+	// ID identifies the credential by the item ID, followed by the section ID for credentials scoped to a section.
+	ID string
+
+	// Title is the title of the item.
+	Title string
+
+	// Section is the label of the section the credential is scoped to, if any.
+	Section string
+
+	// Vault is the name of the vault holding the item.
+	Vault string
+
+	// Username is the username, unless stripped like in the cache.
+	Username string
+
+	// Hosts and Paths list the remotes the credential is used for.
+	Hosts []string
+	Paths []string
+
+	// UpdatedAt is the time the item was last updated.
+	UpdatedAt time.Time
+
+	// ExpiresAt is the time the credential expires, or zero when it doesn't declare it.
+	ExpiresAt time.Time
+}
+
+// Summarize describes the credential without its secrets.
+func (h *Helper) Summarize(item *opcli.Item) Summary {
+	fields := h.fields()
+	s := Summary{
+		ID:        credentialID(item),
+		Title:     item.Title,
+		Vault:     item.Vault.Name,
+		UpdatedAt: item.UpdatedAt,
+		ExpiresAt: expiresAt(item, fields),
+	}
+	if sc := scope(item); sc != nil {
+		s.Section = sc.Label
+	}
+	if f := fields.username(item); f != nil {
+		s.Username = f.Value
+	}
+	s.Hosts, s.Paths = matchValues(item, fields)
+	return s
+}
+
+// Name returns the title of the item, followed by the section label for credentials scoped to a section.
+func (s Summary) Name() string {
+	if s.Section != "" {
+		return s.Title + " / " + s.Section
+	}
+	return s.Title
+}
+
+// credentials returns the credentials an item holds.
+//
+// Each section declaring its own hostname (see Fields) is a separate credential scoped to the section
+// (see opcli.Item.InSection), with fields outside of any section shared by all of them; for example,
+// a GitHub item with a section per organization. So are the fields outside of any section when they
+// declare a hostname too. Items without such sections are a single credential.
+//
+// The LFS section is never a credential on its own (see lfsCredentials).
+func credentials(item *opcli.Item, fields Fields) []*opcli.Item {
+	if s := lfsSection(item); s != nil {
+		item = withoutSection(item, s.ID)
+	}
+	var creds []*opcli.Item
+	for _, s := range item.Sections {
+		if fields.hostname(&opcli.Item{Fields: item.SectionFields(s.ID)}) == nil {
+			continue
+		}
+		creds = append(creds, item.InSection(s.ID))
+	}
+	if len(creds) == 0 {
+		return []*opcli.Item{item}
+	}
+	if top := (&opcli.Item{Fields: item.SectionFields("")}); fields.hostname(top) != nil {
+		c := *item
+		c.Sections = nil
+		c.Fields = top.Fields
+		creds = append([]*opcli.Item{&c}, creds...)
+	}
+	return creds
+}
+
+// scope returns the section the credential is scoped to by credentials, or nil for credentials spanning the whole item.
+func scope(item *opcli.Item) *opcli.Section {
+	if item.Scope == "" {
+		return nil
+	}
+	for i, s := range item.Sections {
+		if s.ID == item.Scope {
+			return &item.Sections[i]
+		}
+	}
+	return nil
+}
+
+// credentialID identifies the credential by the item ID and the ID of the section it is scoped to.
+func credentialID(item *opcli.Item) string {
+	if s := scope(item); s != nil {
+		return item.ID + "/" + s.ID
+	}
+	return item.ID
+}
+
+// match returns the credentials of the item matching the attributes.
+func (h *Helper) match(attr *Attributes, item *opcli.Item) []*opcli.Item {
+	var found []*opcli.Item
+	for _, c := range candidates(attr, item, h.fields()) {
+		if reason := attr.mismatch(c, h.Fields); reason != "" {
+			h.trace("candidate rejected", "item", h.Summarize(c).Name(), "id", credentialID(c), "reason", reason)
+			continue
+		}
+		h.trace("candidate matches", "item", h.Summarize(c).Name(), "id", credentialID(c))
+		found = append(found, c)
+	}
+	return found
+}
+
+// mightMatch reports whether the item known from the cache or listing holds credentials matching the attributes.
+// Unlike match, it doesn't trace the candidates since the item is usually incomplete.
+func (h *Helper) mightMatch(attr *Attributes, item *opcli.Item) bool {
+	for _, c := range candidates(attr, item, h.fields()) {
+		if attr.MatchFields(c, h.Fields) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials(t *testing.T) {
+	acme := opcli.Section{ID: "Section_1", Label: "Acme"}
+	notes := opcli.Section{ID: "Section_2", Label: "Notes"}
+	tests := []struct {
+		name   string
+		item   *opcli.Item
+		expect []string
+	}{
+		{
+			name: "NoSections",
+			item: &opcli.Item{
+				ID: "foo",
+				Fields: []opcli.Field{
+					{ID: "hostname", Label: "hostname", Value: "foo.com"},
+				},
+			},
+			expect: []string{"foo"},
+		},
+		{
+			name: "SectionWithoutHostname",
+			item: &opcli.Item{
+				ID:       "foo",
+				Sections: []opcli.Section{acme, notes},
+				Fields: []opcli.Field{
+					{ID: "hostname", Label: "hostname", Value: "foo.com"},
+					{ID: "h2xm", Section: notes, Label: "text", Value: "bar"},
+				},
+			},
+			expect: []string{"foo"},
+		},
+		{
+			name: "SingleSectionWithoutHostname",
+			item: &opcli.Item{
+				ID:       "foo",
+				Sections: []opcli.Section{acme},
+				Fields: []opcli.Field{
+					{ID: "hostname", Label: "hostname", Value: "foo.com"},
+					{ID: "h2xm", Section: acme, Label: "token", Value: "bar"},
+				},
+			},
+			expect: []string{"foo"},
+		},
+		{
+			name: "SectionWithHostname",
+			item: &opcli.Item{
+				ID:       "foo",
+				Sections: []opcli.Section{acme, notes},
+				Fields: []opcli.Field{
+					{ID: "username", Label: "username", Value: "qux"},
+					{ID: "h2xm", Section: acme, Label: "hostname", Value: "foo.com"},
+				},
+			},
+			expect: []string{"foo/Section_1"},
+		},
+		{
+			name: "TopLevelAndSectionWithHostname",
+			item: &opcli.Item{
+				ID:       "foo",
+				Sections: []opcli.Section{acme, notes},
+				Fields: []opcli.Field{
+					{ID: "hostname", Label: "hostname", Value: "bar.com"},
+					{ID: "h2xm", Section: acme, Label: "hostname", Value: "foo.com"},
+				},
+			},
+			expect: []string{"foo", "foo/Section_1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []string
+			for _, c := range credentials(test.item, DefaultFields) {
+				ids = append(ids, credentialID(c))
+			}
+			assert.Equal(t, test.expect, ids)
+		})
+	}
+}
+
+func TestSummarizeScope(t *testing.T) {
+	git := opcli.Section{ID: "Section_1", Label: "Git"}
+	item := &opcli.Item{
+		ID:       "foo",
+		Title:    "GitHub",
+		Sections: []opcli.Section{git},
+		Fields: []opcli.Field{
+			{ID: "hostname", Label: "hostname", Value: "github.com"},
+			{ID: "h2xm", Section: git, Label: "token", Value: "bar"},
+		},
+	}
+	h := &Helper{}
+	assert.Equal(t, "GitHub", h.Summarize(item).Name())
+	assert.Equal(t, "foo", h.Summarize(item).ID)
+
+	item.Fields = append(item.Fields, opcli.Field{ID: "o5ry", Section: git, Label: "hostname", Value: "github.com"})
+	scoped := credentials(item, DefaultFields)
+	if assert.Len(t, scoped, 2) {
+		assert.Equal(t, "GitHub / Git", h.Summarize(scoped[1]).Name())
+		assert.Equal(t, "foo/Section_1", h.Summarize(scoped[1]).ID)
+	}
+}
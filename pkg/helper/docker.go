@@ -0,0 +1,149 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ErrDockerNotFound is returned when there is no credential for a registry.
+// Docker recognizes the exact message, so it must not be wrapped.
+var ErrDockerNotFound = errors.New("credentials not found in native keychain")
+
+// DockerCredentials is a registry credential in the Docker credential helper protocol.
+// See https://github.com/docker/docker-credential-helpers.
+type DockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+
+	// Username is either the username, or <token> for identity tokens.
+	Username string `json:"Username"`
+
+	Secret string `json:"Secret"`
+}
+
+// ServeDocker runs an action of the Docker credential helper protocol, reading its input from r and writing the result to w.
+//
+// Registries are matched like git remotes, by the host and path of the server URL (e.g. https://index.docker.io/v1/),
+// so the same items, rules, and settings serve both.
+func (h *Helper) ServeDocker(action string, r io.Reader, w io.Writer) error {
+	switch action {
+	case "get":
+		return h.dockerGet(r, w)
+	case "store":
+		return h.dockerStore(r)
+	case "erase":
+		return h.dockerErase(r)
+	case "list":
+		return h.dockerList(w)
+	default:
+		return fmt.Errorf("unknown credential action %q", action)
+	}
+}
+
+func (h *Helper) dockerGet(r io.Reader, w io.Writer) error {
+	serverURL, attr, err := readServerURL(r)
+	if err != nil {
+		return err
+	}
+	res, err := h.Run(Get, attr)
+	if err != nil {
+		return err
+	}
+	if res == nil || res.Password == "" {
+		return ErrDockerNotFound
+	}
+	return json.NewEncoder(w).Encode(DockerCredentials{
+		ServerURL: serverURL,
+		Username:  res.Username,
+		Secret:    res.Password,
+	})
+}
+
+func (h *Helper) dockerStore(r io.Reader) error {
+	var c DockerCredentials
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return fmt.Errorf("invalid credentials: %w", err)
+	}
+	attr, err := dockerAttributes(c.ServerURL)
+	if err != nil {
+		return err
+	}
+	attr.Username, attr.Password = c.Username, c.Secret
+	_, err = h.Run(Store, attr)
+	return err
+}
+
+// dockerErase archives the item holding the registry credential, since docker logout asks to forget it
+// (see Resolution.Remove). Unlike git, Docker never erases credentials on its own.
+func (h *Helper) dockerErase(r io.Reader) error {
+	_, attr, err := readServerURL(r)
+	if err != nil {
+		return err
+	}
+	res, err := h.Resolve(attr)
+	if err != nil {
+		return err
+	}
+	if res.Reference == nil && res.Item == nil {
+		return ErrDockerNotFound
+	}
+	return res.Remove()
+}
+
+// dockerList writes the server URLs of all registry credentials with their usernames.
+// Like Export, it fetches all items since the cache holds no usernames.
+func (h *Helper) dockerList(w io.Writer) error {
+	exported, err := h.Export()
+	if err != nil {
+		return err
+	}
+	list := map[string]string{}
+	for _, e := range exported {
+		u := url.URL{Scheme: e.Credential.Protocol, Host: e.Credential.Host}
+		if e.Credential.Path != "" {
+			u.Path = "/" + e.Credential.Path
+		}
+		if _, ok := list[u.String()]; !ok {
+			list[u.String()] = e.Credential.Username
+		}
+	}
+	return json.NewEncoder(w).Encode(list)
+}
+
+// readServerURL reads the server URL passed on stdin to the get and erase actions.
+func readServerURL(r io.Reader) (string, *Attributes, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", nil, err
+	}
+	serverURL := strings.TrimSpace(string(b))
+	attr, err := dockerAttributes(serverURL)
+	return serverURL, attr, err
+}
+
+// dockerAttributes returns the attributes describing the registry at the server URL,
+// which may omit the scheme (e.g. registry.example.com:5000).
+func dockerAttributes(serverURL string) (*Attributes, error) {
+	if serverURL == "" {
+		return nil, errors.New("missing server URL")
+	}
+	s := serverURL
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q", serverURL)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported server URL %q: only https registries are supported", serverURL)
+	}
+	return &Attributes{
+		Protocol: u.Scheme,
+		Host:     u.Host,
+		Path:     strings.Trim(u.Path, "/"),
+	}, nil
+}
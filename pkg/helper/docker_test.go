@@ -0,0 +1,113 @@
+package helper
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeDocker(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		in     string
+		out    string
+		err    string
+	}{
+		{
+			name:   "Get",
+			action: "get",
+			in:     "foo.com\n",
+			out:    `{"ServerURL":"foo.com","Username":"qux","Secret":"wat"}` + "\n",
+		},
+		{
+			name:   "GetURL",
+			action: "get",
+			in:     "https://foo.com/v1/",
+			out:    `{"ServerURL":"https://foo.com/v1/","Username":"qux","Secret":"wat"}` + "\n",
+		},
+		{
+			name:   "GetNotFound",
+			action: "get",
+			in:     "baz.com",
+			err:    "credentials not found in native keychain",
+		},
+		{
+			name:   "GetInsecure",
+			action: "get",
+			in:     "http://foo.com",
+			err:    `unsupported server URL "http://foo.com": only https registries are supported`,
+		},
+		{
+			name:   "GetMissingURL",
+			action: "get",
+			err:    "missing server URL",
+		},
+		{
+			name:   "EraseNotFound",
+			action: "erase",
+			in:     "baz.com",
+			err:    "credentials not found in native keychain",
+		},
+		{
+			name:   "List",
+			action: "list",
+			out:    `{"https://bar.com":"bar","https://foo.com":"qux"}` + "\n",
+		},
+		{
+			name:   "StoreInvalid",
+			action: "store",
+			in:     "foo.com",
+			err:    "invalid credentials: invalid character 'o' in literal false (expecting 'a')",
+		},
+		{
+			name:   "Unknown",
+			action: "version",
+			err:    `unknown credential action "version"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_get")
+			h := &Helper{Op: opcli.CLI{Path: op}}
+			var out bytes.Buffer
+			err := h.ServeDocker(test.action, strings.NewReader(test.in), &out)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.out, out.String())
+			} else {
+				assert.EqualError(t, err, test.err)
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
+
+func TestServeDockerErase(t *testing.T) {
+	op := optest.Mock(t, "helper_get")
+	h := &Helper{Op: opcli.CLI{Path: op}}
+	assert.NoError(t, h.ServeDocker("erase", strings.NewReader("foo.com\n"), &bytes.Buffer{}))
+	assert.Equal(t, []string{
+		"item list --categories API Credential --format json --iso-timestamps",
+		"item get kpbhk2zfw6m4pgdwylbbpmkcke --format json --iso-timestamps",
+		"item get q5ajmvdxlpxtk3wr5ga3ohjn7y --format json --iso-timestamps",
+		"item delete kpbhk2zfw6m4pgdwylbbpmkcke --archive",
+	}, optest.Calls(t, op))
+}
+
+func TestServeDockerStore(t *testing.T) {
+	op := optest.Mock(t, "helper_locations")
+	h := &Helper{Op: opcli.CLI{Path: op}, Locations: []Location{{Vault: "Personal"}}}
+	err := h.ServeDocker("store", strings.NewReader(`{"ServerURL":"registry.qux.com:5000","Username":"<token>","Secret":"wat"}`), &bytes.Buffer{})
+	assert.NoError(t, err)
+	assert.Contains(t, optest.Calls(t, op), "item create --vault Personal --format json --iso-timestamps")
+	stdin, err := os.ReadFile(filepath.Join(filepath.Dir(op), "op_stdin"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title":"registry.qux.com:5000","category":"API_CREDENTIAL","fields":[{"id":"username","type":"STRING","label":"username","value":"<token>"},{"id":"credential","type":"CONCEALED","label":"credential","value":"wat"},{"id":"hostname","type":"STRING","label":"hostname","value":"registry.qux.com:5000"}]}`, string(stdin))
+}
@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// DefaultExpiryWarning is how long before a credential expires the helper starts warning about it.
+const DefaultExpiryWarning = 7 * 24 * time.Hour
+
+// expiryLayout is the format of expiry dates.
+const expiryLayout = "2006-01-02"
+
+// parseExpiry parses the value of an expiry field; either a Unix timestamp like 1Password stores dates in,
+// or a date in the YYYY-MM-DD format.
+func parseExpiry(v string) (time.Time, error) {
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	t, err := time.Parse(expiryLayout, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry date %q: expected a Unix timestamp or YYYY-MM-DD", v)
+	}
+	return t, nil
+}
+
+// expiresAt returns the time the credential expires, or zero when it has no valid expiry date.
+func expiresAt(item *opcli.Item, fields Fields) time.Time {
+	f := fields.expiry(item)
+	if f == nil {
+		return time.Time{}
+	}
+	t, _ := parseExpiry(f.Value)
+	return t
+}
+
+// warnExpiry warns about the credential for the remote when it expires within ExpiryWarning.
+func (h *Helper) warnExpiry(attr *Attributes, item *opcli.Item, exp time.Time) {
+	if h.Warnings == nil || h.ExpiryWarning <= 0 || exp.IsZero() {
+		return
+	}
+	name := h.Summarize(item).Name()
+	switch left := time.Until(exp); {
+	case left <= 0:
+		fmt.Fprintf(h.Warnings, "warning: the credential for %s in item %q expired on %s\n", attr.RemoteURL(), name, exp.Format(expiryLayout))
+	case left <= h.ExpiryWarning:
+		fmt.Fprintf(h.Warnings, "warning: the credential for %s in item %q expires in %s (%s)\n", attr.RemoteURL(), name, humanize(left), exp.Format(expiryLayout))
+	}
+}
+
+// humanize returns the duration in days, or hours when less than two days.
+func humanize(d time.Duration) string {
+	if d < 48*time.Hour {
+		return fmt.Sprintf("%d hours", int(d.Round(time.Hour).Hours()))
+	}
+	return fmt.Sprintf("%d days", int(d.Round(24*time.Hour).Hours()/24))
+}
+
+// Expiring returns the credentials in all default locations that expire within the duration or already have,
+// soonest first. Like List, it uses the cache when enabled.
+func (h *Helper) Expiring(within time.Duration) ([]Summary, error) {
+	creds, err := h.List()
+	if err != nil {
+		return nil, err
+	}
+	var expiring []Summary
+	for _, c := range creds {
+		s := h.Summarize(c)
+		if !s.ExpiresAt.IsZero() && time.Until(s.ExpiresAt) <= within {
+			expiring = append(expiring, s)
+		}
+	}
+	slices.SortStableFunc(expiring, func(a, b Summary) int { return a.ExpiresAt.Compare(b.ExpiresAt) })
+	return expiring, nil
+}
@@ -0,0 +1,131 @@
+package helper
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpiry(t *testing.T) {
+	tests := []struct {
+		value  string
+		expect time.Time
+		err    string
+	}{
+		{value: "1577836800", expect: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2027-01-17", expect: time.Date(2027, time.January, 17, 0, 0, 0, 0, time.UTC)},
+		{value: "next week", err: `invalid expiry date "next week": expected a Unix timestamp or YYYY-MM-DD`},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			v, err := parseExpiry(test.value)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, v)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestRunGetExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		warning time.Duration
+		expiry  time.Time
+		warn    string
+	}{
+		{
+			name:    "Valid",
+			host:    "foo.com",
+			warning: DefaultExpiryWarning,
+			expiry:  time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "Expiring",
+			host:    "foo.com",
+			warning: 100 * 365 * 24 * time.Hour,
+			expiry:  time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+			warn:    `warning: the credential for https://foo.com/ in item "Foo API Key" expires in `,
+		},
+		{
+			name:    "Expired",
+			host:    "bar.com",
+			warning: DefaultExpiryWarning,
+			expiry:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			warn:    `warning: the credential for https://bar.com/ in item "Bar API Key" expired on 2020-01-01` + "\n",
+		},
+		{
+			name:   "Disabled",
+			host:   "bar.com",
+			expiry: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "NoExpiry",
+			host:    "baz.com",
+			warning: DefaultExpiryWarning,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_expiry")
+			var warnings bytes.Buffer
+			h := &Helper{Op: opcli.CLI{Path: op}, ExpiryWarning: test.warning, Warnings: &warnings}
+			res, err := h.Run(Get, &Attributes{Protocol: "https", Host: test.host})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, res.Password)
+			assert.True(t, test.expiry.Equal(res.PasswordExpiry), "expected expiry %s, got %s", test.expiry, res.PasswordExpiry)
+			if test.warn == "" {
+				assert.Empty(t, warnings.String())
+			} else {
+				assert.Contains(t, warnings.String(), test.warn)
+			}
+		})
+	}
+}
+
+func TestExpiring(t *testing.T) {
+	op := optest.Mock(t, "helper_expiry")
+	h := &Helper{Op: opcli.CLI{Path: op}}
+	expiring, err := h.Expiring(DefaultExpiryWarning)
+	assert.NoError(t, err)
+	assert.Len(t, expiring, 1)
+	assert.Equal(t, "Bar API Key", expiring[0].Title)
+
+	expiring, err = h.Expiring(100 * 365 * 24 * time.Hour)
+	assert.NoError(t, err)
+	var names []string
+	for _, s := range expiring {
+		names = append(names, s.Title)
+	}
+	assert.Equal(t, []string{"Bar API Key", "Foo API Key"}, names, "soonest first, without credentials that never expire")
+}
+
+func TestSetExpiry(t *testing.T) {
+	git := opcli.Section{ID: "Section_git", Label: "Git"}
+	expires := time.Date(2027, time.January, 17, 0, 0, 0, 0, time.UTC)
+	t.Run("EmptyField", func(t *testing.T) {
+		item := &opcli.Item{Fields: []opcli.Field{
+			{ID: "credential", Type: opcli.FieldTypeConcealed, Label: "credential", Value: "wat"},
+			{ID: "expires", Type: opcli.FieldTypeDate, Label: "expires"},
+		}}
+		setExpiry(item, DefaultFields, opcli.Section{}, expires)
+		assert.Equal(t, "1800144000", item.Fields[1].Value)
+		assert.Len(t, item.Fields, 2)
+	})
+	t.Run("Section", func(t *testing.T) {
+		item := &opcli.Item{Sections: []opcli.Section{git}, Fields: []opcli.Field{
+			{ID: "expires", Type: opcli.FieldTypeDate, Label: "expires", Value: "1577836800"},
+			{ID: "x2rc", Section: git, Type: opcli.FieldTypeConcealed, Label: "credential", Value: "wat"},
+		}}
+		setExpiry(item, DefaultFields, git, expires)
+		assert.Equal(t, "1577836800", item.Fields[0].Value, "the expiry of other credentials is kept")
+		assert.Equal(t, opcli.Field{Section: git, Type: opcli.FieldTypeDate, Label: "expires", Value: "1800144000"}, item.Fields[2])
+	})
+}
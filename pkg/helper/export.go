@@ -0,0 +1,70 @@
+package helper
+
+import (
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Exported is a credential for a single remote, with the item it comes from.
+type Exported struct {
+	// Summary describes the credential.
+	Summary Summary
+
+	// Credential holds the remote with the username and password.
+	Credential *Attributes
+}
+
+// Export returns the credentials in all default locations with their secrets, one for each remote
+// a credential declares. Settings overridden for individual remotes and secret references don't apply.
+//
+// Unlike List, it always fetches all items, since the cache holds no secrets.
+func (h *Helper) Export() ([]Exported, error) {
+	var exported []Exported
+	fields := h.fields()
+	for _, l := range h.locations() {
+		p := h.at(l)
+		list, err := p.Op.ListItems(p.filters()...)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range list {
+			item, err := p.Op.GetItem(entry.ID, opcli.WithVault(p.Vault))
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range credentials(item, fields) {
+				exported = append(exported, h.export(c)...)
+			}
+		}
+	}
+	return exported, nil
+}
+
+// export returns the credential for each remote it declares.
+func (h *Helper) export(item *opcli.Item) []Exported {
+	fields := h.fields()
+	s := h.Summarize(item)
+	var password string
+	if f := fields.password(item); f != nil {
+		password = f.Value
+	}
+	paths := s.Paths
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	var exported []Exported
+	for _, host := range s.Hosts {
+		for _, path := range paths {
+			exported = append(exported, Exported{
+				Summary: s,
+				Credential: &Attributes{
+					Protocol: "https",
+					Host:     host,
+					Path:     path,
+					Username: s.Username,
+					Password: password,
+				},
+			})
+		}
+	}
+	return exported
+}
@@ -0,0 +1,52 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelperExport(t *testing.T) {
+	op := optest.Mock(t, "helper_locations")
+	h := &Helper{Op: opcli.CLI{Path: op}, Locations: []Location{{Vault: "Personal"}}}
+	exported, err := h.Export()
+	assert.NoError(t, err)
+	var creds []*Attributes
+	var titles []string
+	for _, e := range exported {
+		creds = append(creds, e.Credential)
+		titles = append(titles, e.Summary.Title)
+	}
+	assert.Equal(t, []*Attributes{
+		{Protocol: "https", Host: "foo.com", Username: "qux", Password: "wat"},
+		{Protocol: "https", Host: "bar.com", Username: "bar", Password: "baz"},
+	}, creds)
+	assert.Equal(t, []string{"Foo API Key", "Bar API Key"}, titles)
+}
+
+func TestHelperExportRemotes(t *testing.T) {
+	h := &Helper{}
+	item := &opcli.Item{
+		ID:    "foo",
+		Title: "Foo",
+		Tags:  []string{"host:foo.com", "host:bar.com", "path:org/a.git", "path:org/b.git"},
+		Fields: []opcli.Field{
+			{ID: "username", Label: "username", Value: "qux"},
+			{ID: "credential", Label: "credential", Value: "wat"},
+		},
+	}
+	var urls []string
+	for _, e := range h.export(item) {
+		assert.Equal(t, "qux", e.Credential.Username)
+		assert.Equal(t, "wat", e.Credential.Password)
+		urls = append(urls, e.Credential.RemoteURL())
+	}
+	assert.Equal(t, []string{
+		"https://foo.com/org/a.git",
+		"https://foo.com/org/b.git",
+		"https://bar.com/org/a.git",
+		"https://bar.com/org/b.git",
+	}, urls)
+}
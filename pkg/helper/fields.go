@@ -0,0 +1,167 @@
+package helper
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Fields maps the credential attributes to item fields.
+//
+// Each attribute lists field selectors tried in order until one resolves to a field with a value:
+//   - `name` selects a field by its ID or label,
+//   - `section/name` selects a field by its ID or label within a section (by section ID or label),
+//   - `purpose:username` or `purpose:password` selects a field by its purpose (e.g., on Login items).
+//
+// Empty attributes default to DefaultFields.
+type Fields struct {
+	// Username lists the fields holding the username.
+	Username []string
+
+	// Password lists the fields holding the password or token.
+	Password []string
+
+	// Hostname lists the fields holding the hostname the credential is used for.
+	Hostname []string
+
+	// Path lists the fields holding the optional path the credential is used for.
+	Path []string
+
+	// Expiry lists the fields holding the optional date the credential expires on.
+	Expiry []string
+}
+
+// DefaultFields matches API Credential items created by the helper, Login items, and custom items with a token field.
+var DefaultFields = Fields{
+	Username: []string{"username", "purpose:username"},
+	Password: []string{"credential", "purpose:password", "token"},
+	Hostname: []string{"hostname"},
+	Path:     []string{"path"},
+	Expiry:   []string{"expires"},
+}
+
+// validateSelector checks the field selector syntax (see Fields).
+func validateSelector(s string) error {
+	if v, ok := strings.CutPrefix(s, "purpose:"); ok {
+		switch strings.ToUpper(v) {
+		case opcli.FieldPurposeUsername, opcli.FieldPurposePassword:
+			return nil
+		default:
+			return fmt.Errorf("invalid field %q: expected purpose:username or purpose:password", s)
+		}
+	}
+	if section, name, ok := strings.Cut(s, "/"); s == "" || ok && (section == "" || name == "") {
+		return fmt.Errorf("invalid field %q: expected [section/]name", s)
+	}
+	return nil
+}
+
+// Merge returns the fields overridden by all non-empty attributes of o.
+func (f Fields) Merge(o Fields) Fields {
+	if len(o.Username) > 0 {
+		f.Username = o.Username
+	}
+	if len(o.Password) > 0 {
+		f.Password = o.Password
+	}
+	if len(o.Hostname) > 0 {
+		f.Hostname = o.Hostname
+	}
+	if len(o.Path) > 0 {
+		f.Path = o.Path
+	}
+	if len(o.Expiry) > 0 {
+		f.Expiry = o.Expiry
+	}
+	return f
+}
+
+// username returns the field holding the username, or nil.
+func (f Fields) username(item *opcli.Item) *opcli.Field {
+	return lookup(item, f.Username)
+}
+
+// password returns the field holding the password, or nil.
+func (f Fields) password(item *opcli.Item) *opcli.Field {
+	return lookup(item, f.Password)
+}
+
+// hostname returns the field holding the hostname, or nil.
+func (f Fields) hostname(item *opcli.Item) *opcli.Field {
+	return lookup(item, f.Hostname)
+}
+
+// path returns the field holding the path, or nil.
+func (f Fields) path(item *opcli.Item) *opcli.Field {
+	return lookup(item, f.Path)
+}
+
+// expiry returns the field holding the expiry date, or nil.
+func (f Fields) expiry(item *opcli.Item) *opcli.Field {
+	return lookup(item, f.Expiry)
+}
+
+// builtinFields lists the fields new items of the category have by default, with their purpose.
+var builtinFields = map[opcli.Category]map[string]opcli.FieldPurpose{
+	opcli.CategoryAPICredential: {"username": "", "credential": "", "hostname": ""},
+	opcli.CategoryLogin:         {"username": opcli.FieldPurposeUsername, "password": opcli.FieldPurposePassword},
+}
+
+// newField returns the field of a new item of the category that the first of the selectors it can hold selects,
+// without a type and value. Login items hold their username and password in the built-in fields with the purpose
+// when one of the selectors selects it, and fields selected by purpose don't exist on other items.
+func newField(category opcli.Category, selectors []string, purpose opcli.FieldPurpose) (opcli.Field, bool) {
+	builtin := builtinFields[category]
+	for name, p := range builtin {
+		if p != "" && p == purpose && slices.ContainsFunc(selectors, func(s string) bool { return strings.EqualFold(s, "purpose:"+string(p)) }) {
+			return opcli.Field{ID: name, Purpose: p, Label: name}, true
+		}
+	}
+	for _, s := range selectors {
+		if strings.HasPrefix(s, "purpose:") {
+			continue
+		}
+		if section, name, ok := strings.Cut(s, "/"); ok {
+			return opcli.Field{Section: opcli.Section{ID: section, Label: section}, Label: name}, true
+		}
+		f := opcli.Field{Label: s}
+		if p, ok := builtin[s]; ok {
+			f.ID, f.Purpose = s, p
+		}
+		return f, true
+	}
+	return opcli.Field{}, false
+}
+
+// isDefault reports whether the metadata of items is read from the default hostname, path, and expiry fields.
+func (f Fields) isDefault() bool {
+	return slices.Equal(f.Hostname, DefaultFields.Hostname) && slices.Equal(f.Path, DefaultFields.Path) && slices.Equal(f.Expiry, DefaultFields.Expiry)
+}
+
+// lookup returns the first field selected by the selectors that has a value.
+func lookup(item *opcli.Item, selectors []string) *opcli.Field {
+	for _, s := range selectors {
+		var f *opcli.Field
+		if v, ok := strings.CutPrefix(s, "purpose:"); ok {
+			purpose := opcli.FieldPurpose(strings.ToUpper(v))
+			if fields := item.FindFields(func(f opcli.Field) bool { return f.Purpose == purpose }); len(fields) > 0 {
+				f = &fields[0]
+			}
+		} else if section, name, ok := strings.Cut(s, "/"); ok {
+			f = item.SectionField(section, name)
+		} else {
+			f = item.Field(s)
+		}
+		if f != nil && f.Value != "" {
+			return f
+		}
+	}
+	return nil
+}
+
+// fields returns the field mapping with defaults applied.
+func (h *Helper) fields() Fields {
+	return DefaultFields.Merge(h.Fields)
+}
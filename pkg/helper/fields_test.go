@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	item := &opcli.Item{
+		Category: opcli.CategoryLogin,
+		Fields: []opcli.Field{
+			{ID: "username", Purpose: opcli.FieldPurposeUsername, Label: "username"},
+			{ID: "password", Purpose: opcli.FieldPurposePassword, Label: "password", Value: "foo"},
+			{ID: "h2xm", Section: opcli.Section{ID: "Section_1", Label: "Git"}, Label: "token", Value: "bar"},
+			{ID: "o5ry", Label: "token", Value: "baz"},
+		},
+	}
+	tests := []struct {
+		name      string
+		selectors []string
+		expect    *opcli.Field
+	}{
+		{
+			name:      "Name",
+			selectors: []string{"password"},
+			expect:    &item.Fields[1],
+		},
+		{
+			name:      "Purpose",
+			selectors: []string{"credential", "purpose:password"},
+			expect:    &item.Fields[1],
+		},
+		{
+			name:      "Section",
+			selectors: []string{"Git/token"},
+			expect:    &item.Fields[2],
+		},
+		{
+			name:      "SectionID",
+			selectors: []string{"Section_1/h2xm"},
+			expect:    &item.Fields[2],
+		},
+		{
+			name:      "SkipEmpty",
+			selectors: []string{"purpose:username", "o5ry"},
+			expect:    &item.Fields[3],
+		},
+		{
+			name:      "NoMatch",
+			selectors: []string{"purpose:username", "Other/token"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expect, lookup(item, test.selectors))
+		})
+	}
+}
+
+func TestValidateSelector(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{value: "token"},
+		{value: "Git/token"},
+		{value: "purpose:USERNAME"},
+		{value: "purpose:password"},
+		{value: "", err: `invalid field "": expected [section/]name`},
+		{value: "Git/", err: `invalid field "Git/": expected [section/]name`},
+		{value: "purpose:notes", err: `invalid field "purpose:notes": expected purpose:username or purpose:password`},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			err := validateSelector(test.value)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelperGenerate(t *testing.T) {
+	rules := []Rule{
+		{Host: "gitea.example.com", Settings: Settings{Recipe: opcli.Recipe{Length: 40, Letters: true, Digits: true}}},
+	}
+	digits := []Rule{
+		{Host: "gitea.example.com", Settings: Settings{Recipe: opcli.Recipe{Length: 8, Digits: true}}},
+	}
+	tests := []struct {
+		name     string
+		rules    []Rule
+		attr     *Attributes
+		password string
+		err      string
+		calls    []string
+	}{
+		{
+			name:     "Recipe",
+			rules:    rules,
+			attr:     &Attributes{Protocol: "https", Host: "gitea.example.com", Username: "ci-bot"},
+			password: "Xq3mT9vLpR2wK7nB4cY8hF6jD1sG5aZ0eU2iO9pQ",
+		},
+		{
+			name:     "DefaultRecipe",
+			attr:     &Attributes{Protocol: "https", Host: "gitea.example.com", Username: "ci-bot"},
+			password: "k#9Vw!2pLr&7Qz@4Tn$8Ys^1Bm*5Hx%3",
+		},
+		{
+			name:  "NotGenerated",
+			rules: digits,
+			attr:  &Attributes{Protocol: "https", Host: "gitea.example.com", Username: "ci-bot"},
+			err:   `op created item "gitea.example.com" without generating a password; the item was deleted`,
+			calls: []string{
+				"item create --generate-password=digits,8 --format json --iso-timestamps",
+				"item delete x7xbdxgbmlgzuwcxdc2ysgbdhe",
+			},
+		},
+		{
+			name: "Existing",
+			attr: &Attributes{Protocol: "https", Host: "foo.com", Username: "ci-bot"},
+			err:  `https://foo.com/ is already in item "Foo API Key"`,
+		},
+		{
+			name: "MissingUsername",
+			attr: &Attributes{Protocol: "https", Host: "gitea.example.com"},
+			err:  "username is required",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_generate")
+			h := &Helper{Op: opcli.CLI{Path: op}, Rules: test.rules}
+			res, item, err := h.Generate(test.attr)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				assert.Nil(t, item)
+				if test.calls != nil {
+					calls := optest.Calls(t, op)
+					assert.Equal(t, test.calls, calls[len(calls)-len(test.calls):])
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.password, res.Password)
+			assert.Equal(t, "ci-bot", res.Username)
+			assert.Equal(t, "gitea.example.com", item.Title)
+			stdin, err := os.ReadFile(filepath.Join(filepath.Dir(op), "op_stdin"))
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"title":"gitea.example.com","category":"API_CREDENTIAL","fields":[{"id":"username","type":"STRING","label":"username","value":"ci-bot"},{"id":"credential","type":"CONCEALED","label":"credential","value":""},{"id":"hostname","type":"STRING","label":"hostname","value":"gitea.example.com"}]}`, string(stdin))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ReadGitConfig reads the settings for a remote URL from git configuration.
+//
+// Settings are read from the `credential.<url>.*` variables using the same URL matching rules as git itself,
+// so the most specific match wins:
+//
+//	[credential "https://github.com/work-org"]
+//		opAccount = work.1password.com
+//		opVault = Engineering
+//		opCategory = API Credential, Login
+//		opTag = git-credential
+//		opItem = GitHub PAT
+//
+// See https://git-scm.com/docs/git-config#Documentation/git-config.txt---get-urlmatch for more details.
+func ReadGitConfig(url string) (Settings, error) {
+	var s Settings
+	cmd := exec.Command("git", "config", "--null", "--get-urlmatch", "credential", url)
+	b, err := cmd.Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) && ee.ExitCode() == 1 {
+			// no matching variables
+			return s, nil
+		}
+		return s, fmt.Errorf("unable to read git config: %w", err)
+	}
+	for _, entry := range bytes.Split(b, []byte{0}) {
+		key, val, _ := strings.Cut(string(entry), "\n")
+		switch key {
+		case "credential.opaccount":
+			s.Account = val
+		case "credential.opvault":
+			s.Vault = val
+		case "credential.opcategory":
+			c, err := parseCategories(strings.Split(val, ","))
+			if err != nil {
+				return s, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			s.Categories = c
+		case "credential.optag":
+			s.Tag = val
+		case "credential.opitem":
+			s.Item = val
+		default:
+			continue
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,92 @@
+package helper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockGitConfig points git to a global configuration file with the given content.
+func mockGitConfig(t *testing.T, config string) {
+	path := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Error(err)
+	}
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", path)
+}
+
+func TestReadGitConfig(t *testing.T) {
+	mockGitConfig(t, `
+[credential]
+	helper = op
+	opVault = Personal
+[credential "https://github.com"]
+	opAccount = personal.1password.com
+[credential "https://github.com/work"]
+	opAccount = work.1password.com
+	opVault = Engineering
+	opCategory = API Credential, login
+	opTag = git-credential
+	opItem = GitHub PAT
+[credential "https://*.example.com"]
+	opCategory = Foo
+`)
+	tests := []struct {
+		name   string
+		url    string
+		expect Settings
+		err    string
+	}{
+		{
+			name:   "Default",
+			url:    "https://gitlab.com/foo/bar.git",
+			expect: Settings{Vault: "Personal"},
+		},
+		{
+			name: "Host",
+			url:  "https://github.com/foo/bar.git",
+			expect: Settings{
+				Account: "personal.1password.com",
+				Vault:   "Personal",
+			},
+		},
+		{
+			name: "Path",
+			url:  "https://github.com/work/bar.git",
+			expect: Settings{
+				Account:    "work.1password.com",
+				Vault:      "Engineering",
+				Categories: []opcli.Category{opcli.CategoryAPICredential, opcli.CategoryLogin},
+				Tag:        "git-credential",
+				Item:       "GitHub PAT",
+			},
+		},
+		{
+			name: "InvalidCategory",
+			url:  "https://git.example.com/foo/bar.git",
+			err:  `invalid credential.opcategory: unknown category: "Foo"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := ReadGitConfig(test.url)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, s)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestReadGitConfigEmpty(t *testing.T) {
+	mockGitConfig(t, "")
+	s, err := ReadGitConfig("https://github.com/foo/bar.git")
+	assert.NoError(t, err)
+	assert.Equal(t, Settings{}, s)
+}
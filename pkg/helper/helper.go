@@ -1,6 +1,14 @@
 package helper
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/gbernady/git-credential-op/pkg/opcli"
 )
 
@@ -19,6 +27,67 @@ type Helper struct {
 
 	// Vault specifies the vault to use.
 	Vault string
+
+	// Locations specifies the accounts and vaults searched for credentials, in order.
+	// When empty, the account of Op and Vault are used.
+	Locations []Location
+
+	// Categories specifies item categories searched for credentials; defaults to API Credential.
+	Categories []opcli.Category
+
+	// Tag restricts the search to items with the tag (e.g., git-credential).
+	Tag string
+
+	// Item specifies the item serving credentials for all remotes, bypassing discovery.
+	Item string
+
+	// Fields maps the credential attributes to item fields; defaults to DefaultFields.
+	Fields Fields
+
+	// Recipe specifies how passwords are generated (see Generate); defaults to the recipe of 1Password CLI.
+	Recipe opcli.Recipe
+
+	// Provider names the git hosting service of the remotes (see forge.For); detected for public instances when empty.
+	Provider string
+
+	// Exclusive stops git from asking other helpers and the user when no credential is found.
+	Exclusive bool
+
+	// Validator checks credentials against the API of the provider before they are returned when set.
+	Validator *Validator
+
+	// ExpiryWarning warns about credentials expiring within the duration when they are used;
+	// disabled when zero. See also Fields.Expiry.
+	ExpiryWarning time.Duration
+
+	// Warnings receives warnings for the user (e.g., os.Stderr) when set.
+	Warnings io.Writer
+
+	// Rules override settings for matching remotes; all matching rules are applied in order.
+	Rules []Rule
+
+	// GitConfig enables reading per-remote settings from git configuration (see ReadGitConfig).
+	// These take precedence over the rules.
+	GitConfig bool
+
+	// Cache enables caching item metadata between runs when set.
+	Cache *Cache
+
+	// Chooser picks one of several matching items, usually by asking the user.
+	Chooser Chooser
+
+	// Fallback selects one of several matching items when the Chooser is not set or unable to ask the user.
+	Fallback Fallback
+
+	// Preferences remembers the items chosen for remotes when set.
+	Preferences *Preferences
+
+	// References map remotes to explicit secret references which take precedence over item discovery.
+	References []Reference
+
+	// Logger traces matching decisions at the debug level when set; secrets are never logged.
+	// Set the Logger of Op as well to trace the op commands.
+	Logger *slog.Logger
 }
 
 // Run executes the requested operation with given attributes.
@@ -27,8 +96,15 @@ type Helper struct {
 // See https://git-scm.com/docs/gitcredentials#_custom_helpers for more details.
 func (h *Helper) Run(o Operation, attr *Attributes) (*Attributes, error) {
 	if attr.Protocol != "https" {
+		h.trace("unsupported protocol", "protocol", attr.Protocol)
 		return nil, nil
 	}
+	h.trace("run", "operation", o, "url", attr.redactedURL(), "username", attr.Username)
+	defer func(start time.Time) { h.trace("done", "operation", o, "duration", time.Since(start)) }(time.Now())
+	h, err := h.forRemote(attr)
+	if err != nil {
+		return nil, err
+	}
 	switch o {
 	case Get:
 		return h.get(attr)
@@ -42,35 +118,340 @@ func (h *Helper) Run(o Operation, attr *Attributes) (*Attributes, error) {
 	}
 }
 
-func (h *Helper) get(attr *Attributes) (*Attributes, error) {
-	list, err := h.Op.ListItems(opcli.WithVault(h.Vault), opcli.WithCategories(opcli.CategoryAPICredential))
+// Serve runs the operation with attributes read from r and writes the response for git to w.
+//
+// A get responds with the credential, with nothing when none is found so that git asks other helpers,
+// or with quit=1 to stop git when the helper is exclusive or the user cancels the selection of an item.
+// Other operations respond with nothing. Errors are returned for the caller to report on stderr.
+// The challenges and unknown attributes of the input are not part of the response (see Attributes.Extra).
+func (h *Helper) Serve(o Operation, r io.Reader, w io.Writer) error {
+	attr, err := ParseAttributes(r)
 	if err != nil {
-		return attr, err
+		return fmt.Errorf("invalid input: %w", err)
+	}
+	res, err := h.Run(o, attr)
+	switch {
+	case o == Get && errors.Is(err, ErrCanceled):
+		res = &Attributes{Quit: true}
+	case err != nil:
+		return err
+	case o != Get || res == nil:
+		return nil
 	}
-	for _, entry := range list {
-		item, err := h.Op.GetItem(entry.ID, opcli.WithVault(h.Vault))
+	// echoing git's capabilities or state would announce them as the helper's own
+	res.WWWAuth, res.Extra = nil, nil
+	_, err = io.WriteString(w, res.String())
+	return err
+}
+
+// forRemote returns a copy of the helper using the settings for the remote;
+// the defaults overridden by matching rules and git configuration, in that order.
+func (h *Helper) forRemote(attr *Attributes) (*Helper, error) {
+	s := h.settings()
+	for _, r := range h.Rules {
+		if r.Match(attr) {
+			h.trace("rule applies", "host", r.Host, "path", r.Path)
+			s = s.Merge(r.Settings)
+		}
+	}
+	if h.GitConfig {
+		gs, err := ReadGitConfig(attr.RemoteURL())
 		if err != nil {
-			return attr, err
+			return nil, err
 		}
-		if attr.Match(item) {
-			if f := item.Field("username"); f != nil {
-				attr.Username = f.Value
-			}
-			if f := item.Field("credential"); f != nil {
-				attr.Password = f.Value
-			}
-			break
+		h.trace("git config", "settings", gs)
+		s = s.Merge(gs)
+	}
+	h.trace("settings", "settings", s)
+	return h.with(s), nil
+}
+
+// trace logs a matching decision when the Logger is set.
+func (h *Helper) trace(msg string, args ...any) {
+	if h.Logger != nil {
+		h.Logger.Debug(msg, args...)
+	}
+}
+
+func (h *Helper) get(attr *Attributes) (*Attributes, error) {
+	res, err := h.resolve(attr)
+	if err != nil {
+		return nil, err
+	}
+	if res.Reference != nil {
+		return h.read(attr, *res.Reference)
+	}
+	item := res.Item
+	var reported time.Time
+	if item != nil && h.Validator != nil {
+		item, reported = h.accepted(attr, res)
+	}
+	if item != nil {
+		fields := h.fields()
+		if f := fields.username(item); f != nil {
+			attr.Username = f.Value
+		}
+		if f := fields.password(item); f != nil {
+			attr.Password = f.Value
+		}
+		// the expiry date in the item takes precedence over the one the provider reports
+		exp := expiresAt(item, fields)
+		if exp.IsZero() {
+			exp = reported
 		}
+		if !exp.IsZero() {
+			attr.PasswordExpiry = exp
+			h.warnExpiry(attr, item, exp)
+		}
+	}
+	if attr.Password == "" {
+		return h.miss(), nil
+	}
+	return attr, nil
+}
+
+// miss returns the response when no credential is found;
+// nothing, so that git asks other helpers, unless the helper is exclusive.
+func (h *Helper) miss() *Attributes {
+	if h.Exclusive {
+		h.trace("no credential, telling git to quit")
+		return &Attributes{Quit: true}
+	}
+	return nil
+}
+
+// validate checks that the item holds a usable credential.
+func (h *Helper) validate(item *opcli.Item) error {
+	fields := h.fields()
+	if f := fields.username(item); f == nil {
+		return fmt.Errorf("item %q has no username field", item.Title)
+	}
+	if f := fields.password(item); f == nil {
+		return fmt.Errorf("item %q has no credential", item.Title)
+	}
+	return nil
+}
+
+// read fills in the credential from secret references.
+func (h *Helper) read(attr *Attributes, r Reference) (*Attributes, error) {
+	if strings.HasPrefix(r.Username, "op://") {
+		v, err := h.Op.Read(r.Username)
+		if err != nil {
+			return nil, err
+		}
+		attr.Username = v
+	} else if r.Username != "" {
+		attr.Username = r.Username
+	}
+	v, err := h.Op.Read(r.Password)
+	if err != nil {
+		return nil, err
 	}
+	attr.Password = v
 	return attr, nil
 }
 
+// store saves the credential git confirmed to work.
+//
+// The password of a matching credential with the same username is updated when it differs, so that a stale token
+// replaced by the user isn't returned again. Otherwise the credential is saved as a new API Credential item in the
+// primary location, unless it is served by a secret reference or a pinned item.
 func (h *Helper) store(attr *Attributes) (*Attributes, error) {
-	// FIXME: use opcli to store api credentials in 1p
-	return nil, nil
+	if attr.Username == "" || attr.Password == "" {
+		return nil, nil
+	}
+	for _, r := range h.References {
+		if r.Match(attr) {
+			h.trace("not stored", "reason", "secret reference", "reference", r.String())
+			return nil, nil
+		}
+	}
+	var (
+		items []*opcli.Item
+		l     Location
+		err   error
+	)
+	if h.Item != "" {
+		var item *opcli.Item
+		if item, l, err = h.pinned(); err != nil {
+			return nil, err
+		}
+		if items = h.match(attr, item); len(items) == 0 {
+			items = credentials(item, h.fields())
+		}
+	} else if items, l, err = h.search(attr); err != nil {
+		return nil, err
+	}
+	fields := h.fields()
+	for _, item := range items {
+		if f := fields.username(item); f != nil && f.Value != attr.Username {
+			continue
+		}
+		if f := fields.password(item); f != nil && f.Value == attr.Password {
+			h.trace("not stored", "reason", "unchanged", "item", h.Summarize(item).Name(), "id", credentialID(item))
+			return nil, nil
+		}
+		h.trace("updating", "item", h.Summarize(item).Name(), "id", credentialID(item))
+		res := &Resolution{Settings: h.settings(), Location: l, Matches: items, Item: item, helper: h}
+		_, err := res.Update(attr.Password, attr.PasswordExpiry)
+		return nil, err
+	}
+	if h.Item != "" {
+		h.trace("not stored", "reason", "pinned item has no credential for the username", "item", h.Item)
+		return nil, nil
+	}
+	_, _, err = h.create(attr)
+	if errors.Is(err, errNotSearched) {
+		if h.Warnings != nil {
+			fmt.Fprintf(h.Warnings, "warning: not storing the credential for %s: %v\n", attr.redactedURL(), err)
+		}
+		return nil, nil
+	}
+	return nil, err
+}
+
+// served explains how the remote is already served, or returns an empty string when it has no credential yet.
+func (h *Helper) served(attr *Attributes) (string, error) {
+	if h.Item != "" {
+		return fmt.Sprintf("served by the pinned item %q", h.Item), nil
+	}
+	for _, r := range h.References {
+		if r.Match(attr) {
+			return fmt.Sprintf("served by the secret reference %s", r.String()), nil
+		}
+	}
+	items, _, err := h.search(attr)
+	if err != nil || len(items) == 0 {
+		return "", err
+	}
+	return fmt.Sprintf("already in item %q", h.Summarize(items[0]).Name()), nil
+}
+
+// errNotSearched is returned when new items would be created in a category the search leaves out,
+// so that they would never be found again.
+var errNotSearched = errors.New("new items are API Credential or Login items, and neither category is searched")
+
+// create stores the credential in a new item in the primary location, returning the item and the location.
+func (h *Helper) create(attr *Attributes, filters ...opcli.Filter) (*opcli.Item, Location, error) {
+	l := h.primary()
+	p := h.at(l)
+	if p.newCategory() == "" {
+		return nil, l, errNotSearched
+	}
+	newItem, err := p.newItem(attr)
+	if err != nil {
+		return nil, l, err
+	}
+	item, err := p.Op.CreateItem(newItem, append([]opcli.Filter{opcli.WithVault(p.Vault)}, filters...)...)
+	if err != nil {
+		return nil, l, err
+	}
+	if p.Cache != nil {
+		_ = p.Cache.Invalidate(p.cacheScope())
+	}
+	return item, l, nil
 }
 
+// Generate stores a new credential for the remote with a password 1Password generates according to the recipe,
+// returning the credential with the password and the item holding it. The remote must not have a credential yet.
+func (h *Helper) Generate(attr *Attributes) (*Attributes, *opcli.Item, error) {
+	if attr.Username == "" {
+		return nil, nil, errors.New("username is required")
+	}
+	h, err := h.forRemote(attr)
+	if err != nil {
+		return nil, nil, err
+	}
+	reason, err := h.served(attr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reason != "" {
+		return nil, nil, fmt.Errorf("%s is %s", attr.RemoteURL(), reason)
+	}
+	h.trace("generating", "url", attr.redactedURL(), "username", attr.Username, "recipe", h.Recipe.String())
+	item, l, err := h.create(attr, opcli.GeneratePassword(h.Recipe))
+	if err != nil {
+		return nil, nil, err
+	}
+	f := h.fields().password(item)
+	if f == nil || f.Value == "" {
+		// an item without a password would serve the remote and block any further attempt
+		p := h.at(l)
+		if err := p.Op.DeleteItem(item.ID); err != nil {
+			return nil, nil, fmt.Errorf("op created item %q without generating a password, and deleting it failed: %w", item.Title, err)
+		}
+		if p.Cache != nil {
+			_ = p.Cache.Invalidate(p.cacheScope())
+		}
+		return nil, nil, fmt.Errorf("op created item %q without generating a password; the item was deleted", item.Title)
+	}
+	res := *attr
+	res.Password = f.Value
+	return &res, item, nil
+}
+
+// newCategory returns the category of new items: API Credential, or Login when the search leaves out API Credential
+// items but not Login items. It returns an empty category when neither is searched.
+func (h *Helper) newCategory() opcli.Category {
+	categories := h.categories()
+	switch {
+	case slices.Contains(categories, opcli.CategoryAPICredential):
+		return opcli.CategoryAPICredential
+	case slices.Contains(categories, opcli.CategoryLogin):
+		return opcli.CategoryLogin
+	}
+	return ""
+}
+
+// newItem returns a new item holding the credential in a searched category (see newCategory), tagged so that
+// the search finds it, with the fields the mapping selects (see Fields.newField).
+// Credentials for Git LFS endpoints are saved for their repository, so that they serve git too.
+func (h *Helper) newItem(attr *Attributes) (*opcli.Item, error) {
+	path := attr.repoPath()
+	item := &opcli.Item{
+		Title:    attr.Host,
+		Category: h.newCategory(),
+		Tags:     h.tags(),
+	}
+	fields := h.fields()
+	values := []struct {
+		name      string
+		selectors []string
+		purpose   opcli.FieldPurpose
+		typ       opcli.FieldType
+		value     string
+	}{
+		{"username", fields.Username, opcli.FieldPurposeUsername, opcli.FieldTypeString, attr.Username},
+		{"password", fields.Password, opcli.FieldPurposePassword, opcli.FieldTypeConcealed, attr.Password},
+		{"hostname", fields.Hostname, "", opcli.FieldTypeString, attr.Host},
+		{"path", fields.Path, "", opcli.FieldTypeString, path},
+	}
+	for _, v := range values {
+		if v.value == "" && v.name == "path" {
+			continue
+		}
+		f, ok := newField(item.Category, v.selectors, v.purpose)
+		if !ok {
+			return nil, fmt.Errorf("new %s items have none of the %s fields %s", item.Category, v.name, strings.Join(v.selectors, ", "))
+		}
+		f.Type, f.Value = v.typ, v.value
+		if f.Section.ID != "" && !slices.Contains(item.Sections, f.Section) {
+			item.Sections = append(item.Sections, f.Section)
+		}
+		item.Fields = append(item.Fields, f)
+	}
+	if path != "" {
+		item.Title = attr.Host + "/" + path
+	}
+	return item, nil
+}
+
+// erase keeps the credential; 1Password items are only archived on request (see Resolution.Remove).
+//
+// Git erases a credential the remote rejected before asking the user for a new one, which store then saves
+// in the same item, keeping its history and other fields.
 func (h *Helper) erase(attr *Attributes) (*Attributes, error) {
-	// FIXME: use opcli to erase api credentials in 1p
+	h.trace("not erased", "reason", "the item is updated by the next store")
 	return nil, nil
 }
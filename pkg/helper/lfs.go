@@ -0,0 +1,98 @@
+package helper
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// lfsPath is the path of the Git LFS API relative to the repository.
+// See https://github.com/git-lfs/git-lfs/blob/main/docs/api/server-discovery.md.
+const lfsPath = "info/lfs"
+
+// lfsLabel is the label of the item section holding the credential for Git LFS.
+const lfsLabel = "LFS"
+
+// IsLFS reports whether the attributes describe a Git LFS API endpoint (e.g. org/repo.git/info/lfs).
+// git-lfs only passes the path when credential.useHttpPath is set for the host.
+func (a *Attributes) IsLFS() bool {
+	return strings.Contains("/"+strings.Trim(a.Path, "/")+"/", "/"+lfsPath+"/")
+}
+
+// repoPath returns the path of the repository, which is the path without the Git LFS API endpoint for LFS requests.
+func (a *Attributes) repoPath() string {
+	p := "/" + strings.Trim(a.Path, "/") + "/"
+	i := strings.Index(p, "/"+lfsPath+"/")
+	if i < 0 {
+		return strings.Trim(a.Path, "/")
+	}
+	return strings.Trim(p[:i], "/")
+}
+
+// lfsSection returns the section holding the credential for Git LFS, or nil.
+func lfsSection(item *opcli.Item) *opcli.Section {
+	for i, s := range item.Sections {
+		if strings.EqualFold(s.Label, lfsLabel) || strings.EqualFold(s.ID, lfsLabel) {
+			return &item.Sections[i]
+		}
+	}
+	return nil
+}
+
+// withoutSection returns a copy of the item without the section and its fields.
+func withoutSection(item *opcli.Item, section string) *opcli.Item {
+	c := *item
+	c.Sections = slices.DeleteFunc(slices.Clone(item.Sections), func(s opcli.Section) bool { return s.ID == section })
+	c.Fields = item.FindFields(func(f opcli.Field) bool { return f.Section.ID != section })
+	return &c
+}
+
+// lfsCredentials returns the variants of the credential used for Git LFS when the item has an LFS section.
+//
+// In both, the fields of the LFS section come first, so they override those of the credential; for example,
+// a separate token for LFS storage. The repo variant keeps the hostname and path of the credential,
+// serving the LFS endpoints of the same repositories. The server variant, for LFS servers on another host,
+// is matched by the hostname and path of the LFS section instead, and is nil without such a hostname.
+func lfsCredentials(item *opcli.Item, cred *opcli.Item, fields Fields) (repo *opcli.Item, server *opcli.Item) {
+	s := lfsSection(item)
+	if s == nil {
+		return nil, nil
+	}
+	lfs := &opcli.Item{Fields: item.SectionFields(s.ID)}
+	r := *cred
+	r.Fields = append(withoutRemote(lfs, fields), cred.Fields...)
+	if fields.hostname(lfs) == nil {
+		return &r, nil
+	}
+	c := *cred
+	c.Fields = append(lfs.Fields, withoutRemote(cred, fields)...)
+	return &r, &c
+}
+
+// withoutRemote returns the fields of the item except those declaring the hostname and path it is used for.
+func withoutRemote(item *opcli.Item, fields Fields) []opcli.Field {
+	var remote []opcli.Field
+	for _, f := range []*opcli.Field{fields.hostname(item), fields.path(item)} {
+		if f != nil {
+			remote = append(remote, *f)
+		}
+	}
+	return item.FindFields(func(f opcli.Field) bool { return !slices.Contains(remote, f) })
+}
+
+// candidates returns the credentials of the item to match against the attributes,
+// using their LFS variants (see lfsCredentials) for Git LFS requests and LFS servers.
+func candidates(attr *Attributes, item *opcli.Item, fields Fields) []*opcli.Item {
+	creds := credentials(item, fields)
+	for i, c := range creds {
+		repo, server := lfsCredentials(item, c, fields)
+		switch {
+		case repo != nil && attr.IsLFS() && attr.MatchFields(repo, fields):
+			creds[i] = repo
+		case server != nil && attr.MatchFields(server, fields):
+			creds[i] = server
+		}
+	}
+	return creds
+}
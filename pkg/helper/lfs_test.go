@@ -0,0 +1,160 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributesIsLFS(t *testing.T) {
+	tests := []struct {
+		path string
+		lfs  bool
+		repo string
+	}{
+		{path: "", repo: ""},
+		{path: "org/repo.git", repo: "org/repo.git"},
+		{path: "org/repo.git/info/lfs", lfs: true, repo: "org/repo.git"},
+		{path: "org/repo.git/info/lfs/objects/batch", lfs: true, repo: "org/repo.git"},
+		{path: "/org/repo/info/lfs/", lfs: true, repo: "org/repo"},
+		{path: "info/lfs", lfs: true, repo: ""},
+		{path: "org/info/lfsx", repo: "org/info/lfsx"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			attr := &Attributes{Protocol: "https", Host: "foo.com", Path: test.path}
+			assert.Equal(t, test.lfs, attr.IsLFS())
+			assert.Equal(t, test.repo, attr.repoPath())
+		})
+	}
+}
+
+func TestMatchRemoteLFS(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"Repository", "org/*", "org/repo.git", true},
+		{"LFSEndpoint", "org/*", "org/repo.git/info/lfs", true},
+		{"LFSObjects", "org/*", "org/repo.git/info/lfs/objects/batch", true},
+		{"OtherRepository", "org/*", "other/repo.git/info/lfs", false},
+		{"LFSPattern", "*/*/info/lfs", "org/repo.git/info/lfs", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attr := &Attributes{Protocol: "https", Host: "foo.com", Path: test.path}
+			assert.Equal(t, test.match, matchRemote("foo.com", test.pattern, attr))
+		})
+	}
+}
+
+func TestCandidatesLFS(t *testing.T) {
+	lfs := opcli.Section{ID: "Section_lfs", Label: "LFS"}
+	item := &opcli.Item{
+		ID:       "foo",
+		Sections: []opcli.Section{lfs},
+		Fields: []opcli.Field{
+			{ID: "username", Label: "username", Value: "git"},
+			{ID: "credential", Label: "credential", Value: "main-token"},
+			{ID: "hostname", Label: "hostname", Value: "foo.com"},
+			{ID: "kx3k", Section: lfs, Label: "credential", Value: "lfs-token"},
+		},
+	}
+	creds := credentials(item, DefaultFields)
+	assert.Len(t, creds, 1, "the LFS section is not a credential on its own")
+	assert.Equal(t, "main-token", DefaultFields.password(creds[0]).Value)
+
+	c := candidates(&Attributes{Protocol: "https", Host: "foo.com", Path: "org/repo.git/info/lfs"}, item, DefaultFields)
+	assert.Len(t, c, 1)
+	assert.Equal(t, "lfs-token", DefaultFields.password(c[0]).Value)
+	assert.Equal(t, "git", DefaultFields.username(c[0]).Value, "fields outside of the LFS section are shared")
+	assert.Equal(t, "foo", credentialID(c[0]))
+}
+
+func TestRunGetLFS(t *testing.T) {
+	tests := []struct {
+		name   string
+		attr   *Attributes
+		expect *Attributes
+	}{
+		{
+			name: "Repository",
+			attr: &Attributes{Protocol: "https", Host: "gitlab.example.com", Path: "org/repo.git"},
+			expect: &Attributes{
+				Protocol: "https",
+				Host:     "gitlab.example.com",
+				Path:     "org/repo.git",
+				Username: "git",
+				Password: "main-token",
+			},
+		},
+		{
+			name: "LFSEndpoint",
+			attr: &Attributes{Protocol: "https", Host: "gitlab.example.com", Path: "org/repo.git/info/lfs"},
+			expect: &Attributes{
+				Protocol: "https",
+				Host:     "gitlab.example.com",
+				Path:     "org/repo.git/info/lfs",
+				Username: "git",
+				Password: "lfs-token",
+			},
+		},
+		{
+			name: "LFSHost",
+			attr: &Attributes{Protocol: "https", Host: "lfs.example.com"},
+			expect: &Attributes{
+				Protocol: "https",
+				Host:     "lfs.example.com",
+				Username: "git",
+				Password: "lfs-token",
+			},
+		},
+		{
+			name: "OtherRepositoryLFS",
+			attr: &Attributes{Protocol: "https", Host: "gitlab.example.com", Path: "other/repo.git/info/lfs"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_lfs")
+			h := &Helper{Op: opcli.CLI{Path: op}}
+			res, err := h.Run(Get, test.attr)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expect, res)
+		})
+	}
+}
+
+func TestNewItemLFS(t *testing.T) {
+	item, err := (&Helper{}).newItem(&Attributes{Protocol: "https", Host: "foo.com", Path: "org/repo.git/info/lfs", Username: "git", Password: "bar"})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.com/org/repo.git", item.Title)
+	assert.Equal(t, "org/repo.git", item.Field("path").Value)
+}
+
+func TestMetadataLFS(t *testing.T) {
+	lfs := opcli.Section{ID: "Section_lfs", Label: "LFS"}
+	item := &opcli.Item{
+		ID:       "foo",
+		Sections: []opcli.Section{lfs},
+		Fields: []opcli.Field{
+			{ID: "credential", Label: "credential", Value: "main-token"},
+			{ID: "hostname", Label: "hostname", Value: "foo.com"},
+			{ID: "kx3k", Section: lfs, Label: "credential", Value: "lfs-token"},
+			{ID: "z3tq", Section: lfs, Label: "hostname", Value: "lfs.foo.com"},
+		},
+	}
+	m := metadata(item, DefaultFields)
+	assert.Equal(t, []opcli.Field{
+		{ID: "hostname", Label: "hostname", Value: "foo.com"},
+		{ID: "z3tq", Section: lfs, Label: "hostname", Value: "lfs.foo.com"},
+	}, m.Fields)
+	h := &Helper{}
+	assert.True(t, h.mightMatch(&Attributes{Protocol: "https", Host: "lfs.foo.com"}, &m))
+	assert.True(t, h.mightMatch(&Attributes{Protocol: "https", Host: "foo.com", Path: "org/repo.git/info/lfs"}, &m))
+	assert.False(t, h.mightMatch(&Attributes{Protocol: "https", Host: "bar.com"}, &m))
+}
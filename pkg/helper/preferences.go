@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Preferences remembers which item the user chose for a remote when several items match.
+type Preferences struct {
+	// Path is the location of the preferences file.
+	Path string
+}
+
+// DefaultPreferencesPath returns the default location of the preferences file
+// within $XDG_STATE_HOME (defaults to $HOME/.local/state).
+func DefaultPreferencesPath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "git-credential-op", "preferences.json"), nil
+}
+
+// Get returns the ID of the item preferred for the remote URL.
+func (p *Preferences) Get(url string) (string, bool) {
+	data, err := p.read()
+	if err != nil {
+		return "", false
+	}
+	id, ok := data[url]
+	return id, ok
+}
+
+// Set records the ID of the item preferred for the remote URL.
+func (p *Preferences) Set(url string, id string) error {
+	data, err := p.read()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if data == nil {
+		data = map[string]string{}
+	}
+	data[url] = id
+	return writeJSON(p.Path, data)
+}
+
+func (p *Preferences) read() (map[string]string, error) {
+	b, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]string
+	err = json.Unmarshal(b, &data)
+	return data, err
+}
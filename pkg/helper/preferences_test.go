@@ -0,0 +1,39 @@
+package helper
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferences(t *testing.T) {
+	p := &Preferences{Path: filepath.Join(t.TempDir(), "state", "preferences.json")}
+
+	_, ok := p.Get("https://foo.com/")
+	assert.False(t, ok)
+
+	assert.NoError(t, p.Set("https://foo.com/", "kpbhk2zfw6m4pgdwylbbpmkcke"))
+	assert.NoError(t, p.Set("https://bar.com/", "q5ajmvdxlpxtk3wr5ga3ohjn7y"))
+	assert.NoError(t, p.Set("https://foo.com/", "utfq63h5szb3jeembehuoioc4f"))
+
+	id, ok := p.Get("https://foo.com/")
+	assert.True(t, ok)
+	assert.Equal(t, "utfq63h5szb3jeembehuoioc4f", id)
+	id, ok = p.Get("https://bar.com/")
+	assert.True(t, ok)
+	assert.Equal(t, "q5ajmvdxlpxtk3wr5ga3ohjn7y", id)
+}
+
+func TestDefaultPreferencesPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/foo")
+	t.Setenv("HOME", "/bar")
+	p, err := DefaultPreferencesPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/foo/git-credential-op/preferences.json", p)
+
+	t.Setenv("XDG_STATE_HOME", "")
+	p, err = DefaultPreferencesPath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/bar/.local/state/git-credential-op/preferences.json", p)
+}
@@ -0,0 +1,89 @@
+package helper
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Reference maps remotes to explicit 1Password secret references, bypassing item discovery.
+type Reference struct {
+	// Host is the pattern the remote hostname must match (e.g., github.com or *.example.com).
+	// See path.Match for the pattern syntax.
+	Host string
+
+	// Path is an optional pattern the remote path must match (e.g., org/*).
+	Path string
+
+	// Username is either a secret reference (op://vault/item/field) or a literal username.
+	Username string
+
+	// Password is the secret reference of the password or token.
+	Password string
+}
+
+// ParseReference parses a reference in the `host[/path]=[username,]password` format.
+//
+// For example: github.com/org/*=op://Work/GitHub/username,op://Work/GitHub/token
+func ParseReference(s string) (Reference, error) {
+	pattern, refs, ok := strings.Cut(s, "=")
+	if !ok || pattern == "" || refs == "" {
+		return Reference{}, fmt.Errorf("invalid reference %q: expected host[/path]=[username,]password", s)
+	}
+	var r Reference
+	r.Host, r.Path, _ = strings.Cut(pattern, "/")
+	if u, p, ok := strings.Cut(refs, ","); ok {
+		r.Username, r.Password = u, p
+	} else {
+		r.Password = refs
+	}
+	if err := validatePatterns(r.Host, r.Path); err != nil {
+		return Reference{}, fmt.Errorf("invalid reference %q: %w", s, err)
+	}
+	if !strings.HasPrefix(r.Password, "op://") {
+		return Reference{}, fmt.Errorf("invalid reference %q: password must be a secret reference", s)
+	}
+	return r, nil
+}
+
+// Match checks if the reference applies to given attributes.
+func (r Reference) Match(attr *Attributes) bool {
+	return matchRemote(r.Host, r.Path, attr)
+}
+
+func (r Reference) String() string {
+	pattern := r.Host
+	if r.Path != "" {
+		pattern += "/" + r.Path
+	}
+	if r.Username != "" {
+		return fmt.Sprintf("%s=%s,%s", pattern, r.Username, r.Password)
+	}
+	return fmt.Sprintf("%s=%s", pattern, r.Password)
+}
+
+// matchRemote checks if the remote host and path match given patterns.
+// The path pattern is optional and matches any path when empty. Git LFS endpoints match the path of their repository too.
+func matchRemote(host, pth string, attr *Attributes) bool {
+	if ok, _ := path.Match(host, attr.Host); !ok {
+		return false
+	}
+	if pth == "" {
+		return true
+	}
+	ok, _ := path.Match(pth, strings.Trim(attr.Path, "/"))
+	if !ok && attr.IsLFS() {
+		ok, _ = path.Match(pth, attr.repoPath())
+	}
+	return ok
+}
+
+// validatePatterns checks the syntax of given patterns.
+func validatePatterns(patterns ...string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
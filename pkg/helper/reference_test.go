@@ -0,0 +1,122 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect Reference
+		err    string
+	}{
+		{
+			name:  "Password",
+			value: "github.com=op://Work/GitHub/token",
+			expect: Reference{
+				Host:     "github.com",
+				Password: "op://Work/GitHub/token",
+			},
+		},
+		{
+			name:  "UsernamePassword",
+			value: "*.example.com/org/*=op://Work/Example/username,op://Work/Example/token",
+			expect: Reference{
+				Host:     "*.example.com",
+				Path:     "org/*",
+				Username: "op://Work/Example/username",
+				Password: "op://Work/Example/token",
+			},
+		},
+		{
+			name:  "LiteralUsername",
+			value: "github.com=x-access-token,op://Work/GitHub/token",
+			expect: Reference{
+				Host:     "github.com",
+				Username: "x-access-token",
+				Password: "op://Work/GitHub/token",
+			},
+		},
+		{
+			name:  "MissingPassword",
+			value: "github.com=",
+			err:   `invalid reference "github.com=": expected host[/path]=[username,]password`,
+		},
+		{
+			name:  "LiteralPassword",
+			value: "github.com=foo,bar",
+			err:   `invalid reference "github.com=foo,bar": password must be a secret reference`,
+		},
+		{
+			name:  "InvalidPattern",
+			value: "[github.com=op://Work/GitHub/token",
+			err:   `invalid reference "[github.com=op://Work/GitHub/token": syntax error in pattern`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := ParseReference(test.value)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, r)
+				assert.Equal(t, test.value, r.String())
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestReferenceMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		ref   Reference
+		attr  Attributes
+		match bool
+	}{
+		{
+			name:  "Host",
+			ref:   Reference{Host: "foo.com"},
+			attr:  Attributes{Host: "foo.com", Path: "bar/baz.git"},
+			match: true,
+		},
+		{
+			name:  "HostWildcard",
+			ref:   Reference{Host: "*.foo.com"},
+			attr:  Attributes{Host: "git.foo.com"},
+			match: true,
+		},
+		{
+			name:  "HostMismatch",
+			ref:   Reference{Host: "foo.com"},
+			attr:  Attributes{Host: "bar.com"},
+			match: false,
+		},
+		{
+			name:  "Path",
+			ref:   Reference{Host: "foo.com", Path: "bar/*"},
+			attr:  Attributes{Host: "foo.com", Path: "bar/baz.git"},
+			match: true,
+		},
+		{
+			name:  "PathMismatch",
+			ref:   Reference{Host: "foo.com", Path: "bar/*"},
+			attr:  Attributes{Host: "foo.com", Path: "qux/baz.git"},
+			match: false,
+		},
+		{
+			name:  "PathMissing",
+			ref:   Reference{Host: "foo.com", Path: "bar/*"},
+			attr:  Attributes{Host: "foo.com"},
+			match: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.match, test.ref.Match(&test.attr))
+		})
+	}
+}
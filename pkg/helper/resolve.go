@@ -0,0 +1,115 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Resolution describes how the credential for a remote is resolved, without reading any secret references.
+type Resolution struct {
+	// Settings are the settings used for the remote.
+	Settings Settings
+
+	// Reference is the secret reference serving the credential, if any.
+	Reference *Reference
+
+	// Location is the account and vault the matches were found in.
+	Location Location
+
+	// Matches lists all credentials matching the remote (see credentials).
+	Matches []*opcli.Item
+
+	// Item is the credential chosen among the matches, or nil.
+	Item *opcli.Item
+
+	// Reason explains why the credential was chosen.
+	Reason string
+
+	helper *Helper
+}
+
+// Resolve finds the credential for the remote like the get operation does.
+func (h *Helper) Resolve(attr *Attributes) (*Resolution, error) {
+	h, err := h.forRemote(attr)
+	if err != nil {
+		return nil, err
+	}
+	return h.resolve(attr)
+}
+
+func (h *Helper) resolve(attr *Attributes) (*Resolution, error) {
+	res := &Resolution{Settings: h.settings(), helper: h}
+	for i, r := range h.References {
+		if r.Match(attr) {
+			h.trace("secret reference matches", "reference", r.String())
+			res.Reference = &h.References[i]
+			res.Reason = "secret reference"
+			return res, nil
+		}
+	}
+	var err error
+	if h.Item != "" {
+		var item *opcli.Item
+		if item, res.Location, err = h.pinned(); err != nil {
+			return nil, err
+		}
+		// the pinned item serves all remotes, but one of its sections may be meant for this one
+		if res.Matches = h.match(attr, item); len(res.Matches) == 0 {
+			res.Matches = credentials(item, h.fields())
+		}
+	} else if res.Matches, res.Location, err = h.search(attr); err != nil {
+		return nil, err
+	}
+	if res.Item, res.Reason, err = h.choose(attr, res.Matches); err != nil {
+		return nil, err
+	}
+	if h.Item != "" && res.Item != nil {
+		res.Reason = "pinned item, " + res.Reason
+		if err := h.validate(res.Item); err != nil {
+			return nil, err
+		}
+	}
+	if res.Item != nil {
+		h.trace("credential chosen", "item", h.Summarize(res.Item).Name(), "id", credentialID(res.Item), "reason", res.Reason)
+	} else {
+		h.trace("no credential chosen", "matches", len(res.Matches), "reason", res.Reason)
+	}
+	return res, nil
+}
+
+// Summary describes the chosen credential, which must be set.
+func (r *Resolution) Summary() Summary {
+	return r.helper.Summarize(r.Item)
+}
+
+// Remove archives the item holding the chosen credential, so that it can still be restored in 1Password.
+//
+// Credentials served by secret references or scoped to a section of an item shared with other
+// credentials can't be removed this way.
+func (r *Resolution) Remove() error {
+	switch {
+	case r.Reference != nil:
+		return fmt.Errorf("credential is served by the secret reference %s; remove it from the configuration instead", r.Reference)
+	case r.Item == nil:
+		return errors.New("no credential found")
+	}
+	p := r.helper.at(r.Location)
+	if s := scope(r.Item); s != nil {
+		item, err := p.Op.GetItem(r.Item.ID, opcli.WithVault(p.Vault))
+		if err != nil {
+			return err
+		}
+		if len(credentials(item, p.fields())) > 1 {
+			return fmt.Errorf("credential is the %q section of item %q; remove the section in 1Password instead", s.Label, item.Title)
+		}
+	}
+	if err := p.Op.ArchiveItem(r.Item.ID); err != nil {
+		return err
+	}
+	if p.Cache != nil {
+		_ = p.Cache.Invalidate(p.cacheScope())
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelperResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		helper  Helper
+		attr    *Attributes
+		item    string
+		reason  string
+		matches int
+		ref     bool
+	}{
+		{
+			name:    "Search",
+			attr:    &Attributes{Protocol: "https", Host: "foo.com"},
+			item:    "kpbhk2zfw6m4pgdwylbbpmkcke",
+			reason:  "single match",
+			matches: 1,
+		},
+		{
+			name:   "NoMatch",
+			attr:   &Attributes{Protocol: "https", Host: "baz.com"},
+			reason: "no match",
+		},
+		{
+			name:    "Pinned",
+			helper:  Helper{Item: "Foo API Key"},
+			attr:    &Attributes{Protocol: "https", Host: "baz.com"},
+			item:    "kpbhk2zfw6m4pgdwylbbpmkcke",
+			reason:  "pinned item, single match",
+			matches: 1,
+		},
+		{
+			name: "Reference",
+			helper: Helper{References: []Reference{
+				{Host: "foo.com", Password: "op://Work/GitHub/token"},
+			}},
+			attr:   &Attributes{Protocol: "https", Host: "foo.com"},
+			reason: "secret reference",
+			ref:    true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_get")
+			h := test.helper
+			h.Op = opcli.CLI{Path: op}
+			res, err := h.Resolve(test.attr)
+			assert.NoError(t, err)
+			assert.Equal(t, test.reason, res.Reason)
+			assert.Len(t, res.Matches, test.matches)
+			assert.Equal(t, test.ref, res.Reference != nil)
+			if test.item == "" {
+				assert.Nil(t, res.Item)
+			} else {
+				assert.Equal(t, test.item, res.Item.ID)
+			}
+		})
+	}
+}
+
+func TestHelperList(t *testing.T) {
+	op := optest.Mock(t, "helper_sections")
+	h := &Helper{Op: opcli.CLI{Path: op}}
+	creds, err := h.List()
+	assert.NoError(t, err)
+	var names []string
+	for _, c := range creds {
+		s := h.Summarize(c)
+		names = append(names, s.Name())
+		assert.Equal(t, []string{"github.com"}, s.Hosts)
+		assert.Empty(t, s.Username, "metadata holds no usernames")
+	}
+	assert.Equal(t, []string{"GitHub / Acme", "GitHub / Initech"}, names)
+}
+
+func TestResolutionRemove(t *testing.T) {
+	t.Run("Item", func(t *testing.T) {
+		op := optest.Mock(t, "helper_get")
+		h := &Helper{Op: opcli.CLI{Path: op}}
+		res, err := h.Resolve(&Attributes{Protocol: "https", Host: "foo.com"})
+		assert.NoError(t, err)
+		assert.NoError(t, res.Remove())
+		assert.Equal(t, "item delete kpbhk2zfw6m4pgdwylbbpmkcke --archive", optest.Calls(t, op)[3])
+	})
+	t.Run("Section", func(t *testing.T) {
+		op := optest.Mock(t, "helper_sections")
+		h := &Helper{Op: opcli.CLI{Path: op}}
+		res, err := h.Resolve(&Attributes{Protocol: "https", Host: "github.com", Path: "acme/app.git"})
+		assert.NoError(t, err)
+		assert.EqualError(t, res.Remove(), `credential is the "Acme" section of item "GitHub"; remove the section in 1Password instead`)
+	})
+	t.Run("Reference", func(t *testing.T) {
+		h := &Helper{References: []Reference{{Host: "foo.com", Password: "op://Work/GitHub/token"}}}
+		res, err := h.Resolve(&Attributes{Protocol: "https", Host: "foo.com"})
+		assert.NoError(t, err)
+		assert.EqualError(t, res.Remove(), "credential is served by the secret reference foo.com=op://Work/GitHub/token; remove it from the configuration instead")
+	})
+}
@@ -0,0 +1,124 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/forge"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// ErrNotSaved is returned by Rotate when the new token couldn't be saved in 1Password.
+var ErrNotSaved = errors.New("the new token wasn't saved")
+
+// Update replaces the password of the chosen credential in the item holding it, returning the updated item.
+// The field is edited in place, so 1Password keeps the previous password in the item history.
+// Unless zero, the expiry date is saved too, adding an expiry field next to the password when there is none.
+func (r *Resolution) Update(password string, expires time.Time) (*opcli.Item, error) {
+	switch {
+	case r.Reference != nil:
+		return nil, fmt.Errorf("credential is served by the secret reference %s; update the item it refers to instead", r.Reference)
+	case r.Item == nil:
+		return nil, errors.New("no credential found")
+	}
+	p := r.helper.at(r.Location)
+	fields := p.fields()
+	f := fields.password(r.Item)
+	if f == nil {
+		return nil, fmt.Errorf("item %q has no credential", r.Item.Title)
+	}
+	item, err := p.Op.GetItem(r.Item.ID, opcli.WithVault(p.Vault))
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for i, v := range item.Fields {
+		if v.ID == f.ID && v.Section.ID == f.Section.ID {
+			item.Fields[i].Value = password
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("item %q has no field %q", item.Title, f.Label)
+	}
+	if !expires.IsZero() {
+		setExpiry(item, fields, f.Section, expires)
+	}
+	updated, err := p.Op.EditItem(item, opcli.WithVault(p.Vault))
+	if err != nil {
+		return nil, err
+	}
+	if p.Cache != nil {
+		_ = p.Cache.Invalidate(p.cacheScope())
+	}
+	return updated, nil
+}
+
+// Rotate replaces the token of the chosen credential with a new one created by the provider,
+// and revokes the old token when requested and the provider didn't already.
+//
+// The rotation is returned even when saving the new token fails (see ErrNotSaved), so that it isn't lost.
+func (r *Resolution) Rotate(p forge.Provider, revoke bool) (*forge.Rotation, error) {
+	switch {
+	case r.Reference != nil:
+		return nil, fmt.Errorf("credential is served by the secret reference %s; rotate the token of the item it refers to instead", r.Reference)
+	case r.Item == nil:
+		return nil, errors.New("no credential found")
+	}
+	var username, token string
+	fields := r.helper.fields()
+	if f := fields.username(r.Item); f != nil {
+		username = f.Value
+	}
+	if f := fields.password(r.Item); f != nil {
+		token = f.Value
+	}
+	if token == "" {
+		return nil, fmt.Errorf("item %q has no credential", r.Item.Title)
+	}
+	r.helper.trace("rotating", "provider", p.Name(), "item", r.Summary().Name(), "id", credentialID(r.Item))
+	rot, err := p.Rotate(username, token)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Update(rot.New.Value, rot.New.ExpiresAt); err != nil {
+		return rot, fmt.Errorf("%w: %w", ErrNotSaved, err)
+	}
+	if revoke && !rot.Revoked {
+		if err := p.Revoke(username, token); err != nil {
+			return rot, fmt.Errorf("revoking the old token: %w", err)
+		}
+		rot.Revoked = true
+	}
+	return rot, nil
+}
+
+// setExpiry sets the first expiry field of the section that the mapping selects, even when empty (like the expires
+// field of API Credential items), or adds an expires date field to the section.
+func setExpiry(item *opcli.Item, fields Fields, section opcli.Section, expires time.Time) {
+	value := strconv.FormatInt(expires.Unix(), 10)
+	for _, s := range fields.Expiry {
+		if strings.HasPrefix(s, "purpose:") {
+			continue
+		}
+		sec, name, ok := strings.Cut(s, "/")
+		if !ok {
+			sec, name = section.ID, s
+		}
+		for i, v := range item.Fields {
+			if (v.Section.ID == sec || v.Section.Label == sec) && (v.ID == name || v.Label == name) {
+				item.Fields[i].Value = value
+				return
+			}
+		}
+	}
+	item.Fields = append(item.Fields, opcli.Field{
+		Section: section,
+		Type:    opcli.FieldTypeDate,
+		Label:   DefaultFields.Expiry[0],
+		Value:   value,
+	})
+}
@@ -0,0 +1,147 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/forge"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider checks and rotates tokens without calling any API.
+type fakeProvider struct {
+	revokes bool
+	err     error
+	calls   []string
+
+	// rejects lists the tokens Check rejects, while others are reported to expire at expires.
+	rejects []string
+	expires time.Time
+}
+
+func (p *fakeProvider) Name() string {
+	return "Fake"
+}
+
+func (p *fakeProvider) Check(username, token string) (*forge.Token, error) {
+	p.calls = append(p.calls, "check "+username+" "+token)
+	if p.err != nil {
+		return nil, p.err
+	}
+	if slices.Contains(p.rejects, token) {
+		return nil, fmt.Errorf("Fake API: 401 Unauthorized: %w", forge.ErrInvalidToken)
+	}
+	return &forge.Token{ExpiresAt: p.expires}, nil
+}
+
+func (p *fakeProvider) Rotate(username, token string) (*forge.Rotation, error) {
+	p.calls = append(p.calls, "rotate "+username+" "+token)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &forge.Rotation{
+		Old:     forge.Token{Name: "ci", Scopes: []string{"api"}},
+		New:     forge.Token{Value: "glpat-new", Name: "ci", Scopes: []string{"api"}},
+		Revoked: p.revokes,
+	}, nil
+}
+
+func (p *fakeProvider) Revoke(username, token string) error {
+	p.calls = append(p.calls, "revoke "+username+" "+token)
+	return nil
+}
+
+func TestResolutionRotate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *fakeProvider
+		revoke   bool
+		calls    []string
+		revoked  bool
+		err      string
+	}{
+		{
+			name:     "Keep",
+			provider: &fakeProvider{},
+			calls:    []string{"rotate qux wat"},
+		},
+		{
+			name:     "Revoke",
+			provider: &fakeProvider{},
+			revoke:   true,
+			calls:    []string{"rotate qux wat", "revoke qux wat"},
+			revoked:  true,
+		},
+		{
+			name:     "RevokedByProvider",
+			provider: &fakeProvider{revokes: true},
+			revoke:   true,
+			calls:    []string{"rotate qux wat"},
+			revoked:  true,
+		},
+		{
+			name:     "ProviderError",
+			provider: &fakeProvider{err: errors.New("GitLab API: 401 Unauthorized")},
+			calls:    []string{"rotate qux wat"},
+			err:      "GitLab API: 401 Unauthorized",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_rotate")
+			h := &Helper{Op: opcli.CLI{Path: op}}
+			res, err := h.Resolve(&Attributes{Protocol: "https", Host: "foo.com"})
+			assert.NoError(t, err)
+			rot, err := res.Rotate(test.provider, test.revoke)
+			assert.Equal(t, test.calls, test.provider.calls)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				assert.NotContains(t, optest.Calls(t, op), "item edit kpbhk2zfw6m4pgdwylbbpmkcke --format json --iso-timestamps")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "glpat-new", rot.New.Value)
+			assert.Equal(t, test.revoked, rot.Revoked)
+			assert.Contains(t, optest.Calls(t, op), "item edit kpbhk2zfw6m4pgdwylbbpmkcke --format json --iso-timestamps")
+			stdin, err := os.ReadFile(filepath.Join(filepath.Dir(op), "op_stdin"))
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"title":"Foo API Key","category":"API_CREDENTIAL","fields":[{"id":"username","type":"STRING","label":"username","value":"qux"},{"id":"credential","type":"CONCEALED","label":"credential","value":"glpat-new"},{"id":"hostname","type":"STRING","label":"hostname","value":"foo.com"}]}`, string(stdin))
+		})
+	}
+}
+
+func TestResolutionRotateErrors(t *testing.T) {
+	t.Run("Reference", func(t *testing.T) {
+		h := &Helper{References: []Reference{{Host: "foo.com", Password: "op://Work/GitHub/token"}}}
+		res, err := h.Resolve(&Attributes{Protocol: "https", Host: "foo.com"})
+		assert.NoError(t, err)
+		_, err = res.Rotate(&fakeProvider{}, false)
+		assert.EqualError(t, err, "credential is served by the secret reference foo.com=op://Work/GitHub/token; rotate the token of the item it refers to instead")
+	})
+	t.Run("NotSaved", func(t *testing.T) {
+		op := optest.Mock(t, "helper_get")
+		h := &Helper{Op: opcli.CLI{Path: op}}
+		res, err := h.Resolve(&Attributes{Protocol: "https", Host: "foo.com"})
+		assert.NoError(t, err)
+		p := &fakeProvider{}
+		rot, err := res.Rotate(p, true)
+		assert.ErrorIs(t, err, ErrNotSaved)
+		assert.Equal(t, "glpat-new", rot.New.Value, "the new token is returned so that it isn't lost")
+		assert.Equal(t, []string{"rotate qux wat"}, p.calls, "the old token is kept")
+	})
+	t.Run("NotFound", func(t *testing.T) {
+		op := optest.Mock(t, "helper_rotate")
+		h := &Helper{Op: opcli.CLI{Path: op}}
+		res, err := h.Resolve(&Attributes{Protocol: "https", Host: "baz.com"})
+		assert.NoError(t, err)
+		_, err = res.Rotate(&fakeProvider{}, false)
+		assert.EqualError(t, err, "no credential found")
+	})
+}
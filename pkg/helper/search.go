@@ -0,0 +1,203 @@
+package helper
+
+import (
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// pinned returns the configured item and the first location containing it.
+// The item may be specified by its name, ID, or sharing link.
+func (h *Helper) pinned() (*opcli.Item, Location, error) {
+	var first error
+	for _, l := range h.locations() {
+		p := h.at(l)
+		item, err := p.Op.GetItem(h.Item, opcli.WithVault(p.Vault))
+		if err == nil {
+			return item, l, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return nil, Location{}, first
+}
+
+// search returns all credentials matching the attributes in the first location with any match,
+// searching all locations in order (see credentials).
+func (h *Helper) search(attr *Attributes) ([]*opcli.Item, Location, error) {
+	for _, l := range h.locations() {
+		h.trace("searching", "location", l.String(), "account", l.Account)
+		items, err := h.at(l).find(attr)
+		if err != nil || len(items) > 0 {
+			return items, l, err
+		}
+	}
+	return nil, Location{}, nil
+}
+
+// find returns all credentials matching the attributes.
+//
+// With the cache enabled, a fresh cache is consulted first so that only the matching items need to be fetched.
+// Otherwise the items are listed and only those whose version has changed since they were cached are fetched.
+func (h *Helper) find(attr *Attributes) ([]*opcli.Item, error) {
+	var cached []opcli.Item
+	if h.Cache != nil {
+		var fresh bool
+		cached, fresh = h.Cache.Load(h.cacheScope())
+		h.trace("cache", "scope", h.cacheScope(), "items", len(cached), "fresh", fresh)
+		if fresh {
+			if items, ok := h.findCached(attr, cached); ok {
+				return items, nil
+			}
+			h.trace("cache miss or out of date")
+		}
+	}
+
+	versions := make(map[string]opcli.Item, len(cached))
+	for _, entry := range cached {
+		versions[entry.ID] = entry
+	}
+	list, err := h.Op.ListItems(h.filters()...)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		found []*opcli.Item
+		meta  []opcli.Item
+	)
+	for _, entry := range list {
+		// skip fetching items the cache knows in their current version not to match
+		if known, ok := versions[entry.ID]; ok && known.Version == entry.Version && !h.mightMatch(attr, &known) {
+			h.trace("candidate not fetched", "item", entry.Title, "id", entry.ID, "reason", "cached metadata does not match")
+			meta = append(meta, known)
+			continue
+		}
+		item, err := h.Op.GetItem(entry.ID, opcli.WithVault(h.Vault))
+		if err != nil {
+			return nil, err
+		}
+		meta = append(meta, metadata(item, h.fields()))
+		found = append(found, h.match(attr, item)...)
+	}
+	if h.Cache != nil {
+		// a failed write only costs a cache miss later, so it must not fail the lookup
+		_ = h.Cache.Store(h.cacheScope(), meta)
+	}
+	return found, nil
+}
+
+// List returns the credentials in all default locations, in order, stripped of secrets like the cache.
+// Settings overridden for individual remotes don't apply.
+func (h *Helper) List() ([]*opcli.Item, error) {
+	var creds []*opcli.Item
+	for _, l := range h.locations() {
+		items, err := h.at(l).catalog()
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			creds = append(creds, credentials(&items[i], h.fields())...)
+		}
+	}
+	return creds, nil
+}
+
+// catalog returns the metadata of all candidate items, fetching only those not cached in their current version.
+func (h *Helper) catalog() ([]opcli.Item, error) {
+	var cached []opcli.Item
+	if h.Cache != nil {
+		var fresh bool
+		if cached, fresh = h.Cache.Load(h.cacheScope()); fresh {
+			return cached, nil
+		}
+	}
+	versions := make(map[string]opcli.Item, len(cached))
+	for _, entry := range cached {
+		versions[entry.ID] = entry
+	}
+	list, err := h.Op.ListItems(h.filters()...)
+	if err != nil {
+		return nil, err
+	}
+	var meta []opcli.Item
+	for _, entry := range list {
+		if known, ok := versions[entry.ID]; ok && known.Version == entry.Version {
+			meta = append(meta, known)
+			continue
+		}
+		item, err := h.Op.GetItem(entry.ID, opcli.WithVault(h.Vault))
+		if err != nil {
+			return nil, err
+		}
+		meta = append(meta, metadata(item, h.fields()))
+	}
+	if h.Cache != nil {
+		_ = h.Cache.Store(h.cacheScope(), meta)
+	}
+	return meta, nil
+}
+
+// findCached fetches the items matching the attributes according to the cache.
+// It reports false when there is no match or the cache turns out to be out of date.
+func (h *Helper) findCached(attr *Attributes, cached []opcli.Item) ([]*opcli.Item, bool) {
+	var found []*opcli.Item
+	for _, entry := range cached {
+		if !h.mightMatch(attr, &entry) {
+			continue
+		}
+		item, err := h.Op.GetItem(entry.ID, opcli.WithVault(h.Vault))
+		if err != nil || item.Version != entry.Version {
+			return nil, false
+		}
+		matches := h.match(attr, item)
+		if len(matches) == 0 {
+			return nil, false
+		}
+		found = append(found, matches...)
+	}
+	return found, len(found) > 0
+}
+
+// filters returns the filters listing candidate items.
+func (h *Helper) filters() []opcli.Filter {
+	return []opcli.Filter{
+		opcli.WithVault(h.Vault),
+		opcli.WithCategories(h.categories()...),
+		opcli.WithTags(h.tags()...),
+	}
+}
+
+// tags returns the tags candidate items must have.
+func (h *Helper) tags() []string {
+	if h.Tag == "" {
+		return nil
+	}
+	return []string{h.Tag}
+}
+
+// categories returns the item categories searched for credentials.
+func (h *Helper) categories() []opcli.Category {
+	if len(h.Categories) == 0 {
+		return []opcli.Category{opcli.CategoryAPICredential}
+	}
+	return h.Categories
+}
+
+// cacheScope identifies the listing cached for the configured account, vault, categories, tag,
+// and custom hostname and path fields.
+func (h *Helper) cacheScope() string {
+	var c []string
+	for _, v := range h.categories() {
+		c = append(c, string(v))
+	}
+	scope := h.Op.Account + "/" + h.Vault + "/" + strings.Join(c, ",")
+	if h.Tag != "" {
+		scope += "/" + h.Tag
+	}
+	if f := h.fields(); !f.isDefault() {
+		// cached metadata only holds the fields matched on, so a different mapping needs its own listing
+		scope += "#" + strings.Join(f.Hostname, ",") + ";" + strings.Join(f.Path, ",") + ";" + strings.Join(f.Expiry, ",")
+	}
+	return scope
+}
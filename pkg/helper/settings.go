@@ -0,0 +1,218 @@
+package helper
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// Settings holds the helper configuration that can be overridden for individual remotes.
+type Settings struct {
+	// Account specifies the account to use.
+	Account string
+
+	// Vault specifies the vault to use.
+	Vault string
+
+	// Categories specifies item categories searched for credentials.
+	Categories []opcli.Category
+
+	// Tag restricts the search to items with the tag.
+	Tag string
+
+	// Item specifies the item (name, ID, or sharing link) serving the credential, bypassing discovery.
+	Item string
+
+	// Locations specifies the accounts and vaults searched for credentials, in order.
+	// When set, it takes precedence over Account and Vault.
+	Locations []Location
+
+	// Fields maps the credential attributes to item fields.
+	Fields Fields
+
+	// Recipe specifies how passwords are generated.
+	Recipe opcli.Recipe
+
+	// Provider names the git hosting service of the remote (see forge.For).
+	Provider string
+
+	// Exclusive stops git from asking other helpers and the user when no credential is found.
+	Exclusive bool
+}
+
+// LogValue logs the non-empty settings.
+func (s Settings) LogValue() slog.Value {
+	var attrs []slog.Attr
+	for _, a := range []struct{ key, value string }{
+		{"account", s.Account},
+		{"vault", s.Vault},
+		{"tag", s.Tag},
+		{"item", s.Item},
+		{"provider", s.Provider},
+	} {
+		if a.value != "" {
+			attrs = append(attrs, slog.String(a.key, a.value))
+		}
+	}
+	if len(s.Categories) > 0 {
+		attrs = append(attrs, slog.Any("categories", s.Categories))
+	}
+	if len(s.Locations) > 0 {
+		attrs = append(attrs, slog.Any("locations", s.Locations))
+	}
+	if r := s.Recipe.String(); r != "" {
+		attrs = append(attrs, slog.String("recipe", r))
+	}
+	if s.Exclusive {
+		attrs = append(attrs, slog.Bool("exclusive", true))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Location is an account and vault searched for credentials.
+type Location struct {
+	// Account specifies the account to use; defaults to the account op is signed in to.
+	Account string
+
+	// Vault specifies the vault to use.
+	Vault string
+
+	// Primary designates the location new credentials are stored in.
+	// When no location is designated, the first one is used.
+	Primary bool
+}
+
+// ParseLocation parses a location in the `[account:]vault` format.
+func ParseLocation(s string) (Location, error) {
+	var l Location
+	if a, v, ok := strings.Cut(s, ":"); ok {
+		l.Account, l.Vault = a, v
+	} else {
+		l.Vault = s
+	}
+	if l.Vault == "" {
+		return Location{}, fmt.Errorf("invalid location %q: expected [account:]vault", s)
+	}
+	return l, nil
+}
+
+func (l Location) String() string {
+	if l.Account != "" {
+		return l.Account + ":" + l.Vault
+	}
+	return l.Vault
+}
+
+// Rule overrides settings for remotes matching its host and path patterns.
+type Rule struct {
+	// Host is the pattern the remote hostname must match (see path.Match).
+	Host string
+
+	// Path is an optional pattern the remote path must match.
+	Path string
+
+	Settings
+}
+
+// Match checks if the rule applies to given attributes.
+func (r Rule) Match(attr *Attributes) bool {
+	return matchRemote(r.Host, r.Path, attr)
+}
+
+// Merge returns the settings overridden by all non-empty values of o.
+func (s Settings) Merge(o Settings) Settings {
+	if o.Account != "" {
+		s.Account = o.Account
+	}
+	if o.Vault != "" {
+		s.Vault = o.Vault
+	}
+	if len(o.Locations) > 0 {
+		s.Locations = o.Locations
+	} else if o.Account != "" || o.Vault != "" {
+		// an explicit account or vault narrows the search down to it
+		s.Locations = nil
+	}
+	if len(o.Categories) > 0 {
+		s.Categories = o.Categories
+	}
+	if o.Tag != "" {
+		s.Tag = o.Tag
+	}
+	if o.Item != "" {
+		s.Item = o.Item
+	}
+	s.Fields = s.Fields.Merge(o.Fields)
+	if o.Recipe != (opcli.Recipe{}) {
+		s.Recipe = o.Recipe
+	}
+	if o.Provider != "" {
+		s.Provider = o.Provider
+	}
+	if o.Exclusive {
+		s.Exclusive = true
+	}
+	return s
+}
+
+// settings returns the current helper settings.
+func (h *Helper) settings() Settings {
+	return Settings{
+		Account:    h.Op.Account,
+		Vault:      h.Vault,
+		Categories: h.Categories,
+		Tag:        h.Tag,
+		Item:       h.Item,
+		Locations:  h.Locations,
+		Fields:     h.Fields,
+		Recipe:     h.Recipe,
+		Provider:   h.Provider,
+		Exclusive:  h.Exclusive,
+	}
+}
+
+// with returns a copy of the helper using given settings.
+func (h *Helper) with(s Settings) *Helper {
+	c := *h
+	c.Op.Account = s.Account
+	c.Vault = s.Vault
+	c.Categories = s.Categories
+	c.Tag = s.Tag
+	c.Item = s.Item
+	c.Locations = s.Locations
+	c.Fields = s.Fields
+	c.Recipe = s.Recipe
+	c.Provider = s.Provider
+	c.Exclusive = s.Exclusive
+	return &c
+}
+
+// locations returns the locations searched for credentials, in order.
+func (h *Helper) locations() []Location {
+	if len(h.Locations) == 0 {
+		return []Location{{Account: h.Op.Account, Vault: h.Vault, Primary: true}}
+	}
+	return h.Locations
+}
+
+// primary returns the location new credentials are stored in.
+func (h *Helper) primary() Location {
+	locations := h.locations()
+	for _, l := range locations {
+		if l.Primary {
+			return l
+		}
+	}
+	return locations[0]
+}
+
+// at returns a copy of the helper using a single location.
+func (h *Helper) at(l Location) *Helper {
+	c := *h
+	c.Op.Account = l.Account
+	c.Vault = l.Vault
+	c.Locations = nil
+	return &c
+}
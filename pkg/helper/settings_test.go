@@ -0,0 +1,93 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect Location
+		err    string
+	}{
+		{
+			name:   "Vault",
+			value:  "Personal",
+			expect: Location{Vault: "Personal"},
+		},
+		{
+			name:   "AccountVault",
+			value:  "work.1password.com:Team",
+			expect: Location{Account: "work.1password.com", Vault: "Team"},
+		},
+		{
+			name:  "MissingVault",
+			value: "work.1password.com:",
+			err:   `invalid location "work.1password.com:": expected [account:]vault`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			l, err := ParseLocation(test.value)
+			if test.err == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, test.expect, l)
+				assert.Equal(t, test.value, l.String())
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestHelperPrimary(t *testing.T) {
+	h := &Helper{Op: opcli.CLI{Account: "foo"}, Vault: "bar"}
+	assert.Equal(t, Location{Account: "foo", Vault: "bar", Primary: true}, h.primary())
+	h.Locations = []Location{{Vault: "baz"}, {Vault: "qux"}}
+	assert.Equal(t, Location{Vault: "baz"}, h.primary())
+	h.Locations[1].Primary = true
+	assert.Equal(t, Location{Vault: "qux", Primary: true}, h.primary())
+}
+
+func TestSettingsMergeLocations(t *testing.T) {
+	s := Settings{
+		Locations: []Location{{Vault: "foo"}, {Vault: "bar"}},
+	}
+	assert.Equal(t, s.Locations, s.Merge(Settings{Item: "qux"}).Locations)
+	assert.Equal(t, Settings{Vault: "baz"}, s.Merge(Settings{Vault: "baz"}))
+	assert.Equal(t, Settings{Locations: []Location{{Vault: "baz"}}}, s.Merge(Settings{Locations: []Location{{Vault: "baz"}}}))
+}
+
+func TestSettingsMerge(t *testing.T) {
+	s := Settings{
+		Account:    "foo",
+		Vault:      "bar",
+		Categories: []opcli.Category{opcli.CategoryAPICredential},
+	}
+	assert.Equal(t, s, s.Merge(Settings{}))
+	assert.Equal(t, Settings{
+		Account:    "foo",
+		Vault:      "baz",
+		Categories: []opcli.Category{opcli.CategoryLogin},
+		Item:       "qux",
+	}, s.Merge(Settings{Vault: "baz", Categories: []opcli.Category{opcli.CategoryLogin}, Item: "qux"}))
+}
+
+func TestSettingsMergeFields(t *testing.T) {
+	s := Settings{
+		Fields: Fields{
+			Username: []string{"login"},
+			Password: []string{"token"},
+		},
+	}
+	assert.Equal(t, s, s.Merge(Settings{}))
+	assert.Equal(t, Fields{
+		Username: []string{"login"},
+		Password: []string{"Git/token"},
+		Hostname: []string{"server"},
+	}, s.Merge(Settings{Fields: Fields{Password: []string{"Git/token"}, Hostname: []string{"server"}}}).Fields)
+}
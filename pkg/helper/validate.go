@@ -0,0 +1,158 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gbernady/git-credential-op/pkg/forge"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+)
+
+// DefaultValidationTTL is how long the results of validating credentials are cached.
+const DefaultValidationTTL = 5 * time.Minute
+
+// Validator checks credentials against the API of the git hosting service of the remote (see forge.Provider.Check),
+// so that a stale token is never returned; git would call erase after the authentication fails and ask again.
+//
+// Remotes of unknown providers and credentials that can't be checked (e.g. the API is unreachable) are assumed valid.
+type Validator struct {
+	// Path is the location of the file caching the results; they are not cached when empty.
+	// Only hashes of the credentials are stored, never the credentials themselves.
+	Path string
+
+	// TTL specifies how long the results are cached.
+	TTL time.Duration
+
+	// Providers returns the provider of the host by name (see Helper.Provider); defaults to forge.For.
+	Providers func(host, name string) (forge.Provider, error)
+}
+
+// validation is a cached result of validating a credential.
+type validation struct {
+	Valid     bool      `json:"valid"`
+	CheckedAt time.Time `json:"checked_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// DefaultValidationPath returns the default location of the validation cache within the user cache directory.
+func DefaultValidationPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "git-credential-op", "validations.json"), nil
+}
+
+// provider returns the provider of the remote, or nil when it's unknown.
+func (v *Validator) provider(host, name string) forge.Provider {
+	providers := v.Providers
+	if providers == nil {
+		providers = forge.For
+	}
+	p, err := providers(host, name)
+	if err != nil {
+		return nil
+	}
+	return p
+}
+
+// check validates the credential with the provider unless the result is cached.
+func (v *Validator) check(p forge.Provider, host, username, password string) (validation, error) {
+	sum := sha256.Sum256([]byte(p.Name() + "\x00" + host + "\x00" + username + "\x00" + password))
+	key := hex.EncodeToString(sum[:])
+	cached := v.load()
+	if r, ok := cached[key]; ok && time.Since(r.CheckedAt) < v.TTL {
+		return r, nil
+	}
+	r := validation{Valid: true, CheckedAt: time.Now()}
+	t, err := p.Check(username, password)
+	switch {
+	case errors.Is(err, forge.ErrInvalidToken):
+		r.Valid = false
+	case err != nil:
+		return r, err
+	default:
+		r.ExpiresAt = t.ExpiresAt
+	}
+	if v.Path != "" {
+		for k, c := range cached {
+			if time.Since(c.CheckedAt) >= v.TTL {
+				delete(cached, k)
+			}
+		}
+		cached[key] = r
+		_ = writeJSON(v.Path, cached)
+	}
+	return r, nil
+}
+
+// load returns the cached results, treating any error reading the cache as a miss.
+func (v *Validator) load() map[string]validation {
+	cached := map[string]validation{}
+	if v.Path == "" {
+		return cached
+	}
+	if b, err := os.ReadFile(v.Path); err == nil {
+		_ = json.Unmarshal(b, &cached)
+	}
+	if cached == nil {
+		cached = map[string]validation{}
+	}
+	return cached
+}
+
+// accepted returns the first credential the provider of the remote accepts, trying the chosen one before
+// the other matches in order, along with the expiry the provider reports for it. It returns nil when the provider
+// rejects them all, warning about it since git would otherwise only report the authentication failure.
+func (h *Helper) accepted(attr *Attributes, res *Resolution) (*opcli.Item, time.Time) {
+	p := h.Validator.provider(attr.Host, h.Provider)
+	if p == nil {
+		h.trace("validation skipped", "reason", "unknown provider", "host", attr.Host)
+		return res.Item, time.Time{}
+	}
+	order := []*opcli.Item{res.Item}
+	for _, m := range res.Matches {
+		if m != res.Item {
+			order = append(order, m)
+		}
+	}
+	fields := h.fields()
+	var rejected []string
+	for _, c := range order {
+		var username, password string
+		if f := fields.username(c); f != nil {
+			username = f.Value
+		}
+		if f := fields.password(c); f != nil {
+			password = f.Value
+		}
+		if password == "" {
+			continue
+		}
+		r, err := h.Validator.check(p, attr.Host, username, password)
+		if err != nil {
+			h.trace("validation failed", "item", h.Summarize(c).Name(), "id", credentialID(c), "error", err)
+			if h.Warnings != nil {
+				fmt.Fprintf(h.Warnings, "warning: unable to validate the credentials for %s with %s, using them anyway: %v\n", attr.RemoteURL(), p.Name(), err)
+			}
+			return c, time.Time{}
+		}
+		if r.Valid {
+			h.trace("credential valid", "provider", p.Name(), "item", h.Summarize(c).Name(), "id", credentialID(c))
+			return c, r.ExpiresAt
+		}
+		h.trace("credential rejected", "provider", p.Name(), "item", h.Summarize(c).Name(), "id", credentialID(c))
+		rejected = append(rejected, fmt.Sprintf("%q", h.Summarize(c).Name()))
+	}
+	if h.Warnings != nil && len(rejected) > 0 {
+		fmt.Fprintf(h.Warnings, "warning: %s rejected the credentials for %s in %s\n", p.Name(), attr.RemoteURL(), strings.Join(rejected, ", "))
+	}
+	return nil, time.Time{}
+}
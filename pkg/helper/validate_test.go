@@ -0,0 +1,116 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gbernady/git-credential-op/internal/optest"
+	"github.com/gbernady/git-credential-op/pkg/forge"
+	"github.com/gbernady/git-credential-op/pkg/opcli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunGetValidate(t *testing.T) {
+	expires := time.Date(2027, time.January, 17, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		provider *fakeProvider
+		unknown  bool
+		password string
+		expiry   time.Time
+		calls    []string
+		warn     string
+	}{
+		{
+			name:     "Valid",
+			provider: &fakeProvider{expires: expires},
+			password: "ghp_old",
+			expiry:   expires,
+			calls:    []string{"check octocat ghp_old"},
+		},
+		{
+			name:     "Fallback",
+			provider: &fakeProvider{rejects: []string{"ghp_old"}},
+			password: "ghp_new",
+			calls:    []string{"check octocat ghp_old", "check octocat ghp_new"},
+		},
+		{
+			name:     "AllRejected",
+			provider: &fakeProvider{rejects: []string{"ghp_old", "ghp_new"}},
+			calls:    []string{"check octocat ghp_old", "check octocat ghp_new"},
+			warn:     `warning: Fake rejected the credentials for https://github.com/ in "GitHub Old", "GitHub New"` + "\n",
+		},
+		{
+			name:     "Unreachable",
+			provider: &fakeProvider{err: errors.New("dial tcp: connection refused")},
+			password: "ghp_old",
+			calls:    []string{"check octocat ghp_old"},
+			warn:     "warning: unable to validate the credentials for https://github.com/ with Fake, using them anyway: dial tcp: connection refused\n",
+		},
+		{
+			name:     "UnknownProvider",
+			provider: &fakeProvider{},
+			unknown:  true,
+			password: "ghp_old",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			op := optest.Mock(t, "helper_validate")
+			var warnings bytes.Buffer
+			h := &Helper{
+				Op:       opcli.CLI{Path: op},
+				Warnings: &warnings,
+				Validator: &Validator{
+					Providers: func(host, name string) (forge.Provider, error) {
+						if test.unknown {
+							return nil, errors.New("unknown provider")
+						}
+						return test.provider, nil
+					},
+				},
+			}
+			res, err := h.Run(Get, &Attributes{Protocol: "https", Host: "github.com"})
+			assert.NoError(t, err)
+			if test.password == "" {
+				assert.Nil(t, res)
+			} else {
+				assert.Equal(t, test.password, res.Password)
+				assert.Equal(t, test.expiry, res.PasswordExpiry)
+			}
+			assert.Equal(t, test.calls, test.provider.calls)
+			assert.Equal(t, test.warn, warnings.String())
+		})
+	}
+}
+
+func TestValidatorCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "validations.json")
+	p := &fakeProvider{rejects: []string{"ghp_old"}}
+	v := &Validator{Path: path, TTL: time.Minute}
+	for i := 0; i < 2; i++ {
+		r, err := v.check(p, "github.com", "octocat", "ghp_old")
+		assert.NoError(t, err)
+		assert.False(t, r.Valid)
+		r, err = v.check(p, "github.com", "octocat", "ghp_new")
+		assert.NoError(t, err)
+		assert.True(t, r.Valid)
+	}
+	assert.Equal(t, []string{"check octocat ghp_old", "check octocat ghp_new"}, p.calls, "results are cached")
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(b), "ghp_", "only hashes are cached")
+
+	v.TTL = 0
+	_, err = v.check(p, "github.com", "octocat", "ghp_old")
+	assert.NoError(t, err)
+	assert.Len(t, p.calls, 3, "stale results are checked again")
+
+	p.err = errors.New("dial tcp: connection refused")
+	_, err = (&Validator{Path: path, TTL: time.Minute}).check(p, "github.com", "octocat", "ghp_other")
+	assert.EqualError(t, err, "dial tcp: connection refused")
+}
@@ -0,0 +1,24 @@
+package opcli
+
+// Account is a 1Password account the CLI is configured for.
+type Account struct {
+	URL         string `json:"url"`
+	Email       string `json:"email"`
+	UserUUID    string `json:"user_uuid"`
+	AccountUUID string `json:"account_uuid"`
+	UserType    string `json:"user_type"`
+}
+
+// ListAccounts returns the accounts added to the CLI on this device, whether signed in or not.
+func (c *CLI) ListAccounts() ([]Account, error) {
+	var val []Account
+	err := c.execJSON([]string{"account", "list"}, nil, nil, &val)
+	return val, err
+}
+
+// WhoAmI returns the account the CLI is signed in to, or an error if it's not signed in.
+func (c *CLI) WhoAmI() (*Account, error) {
+	var val *Account
+	err := c.execJSON([]string{"whoami"}, nil, nil, &val)
+	return val, err
+}
@@ -0,0 +1,78 @@
+package opcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAccounts(t *testing.T) {
+	tests := []struct {
+		name string
+		resp []Account
+		err  string
+	}{
+		{
+			name: "Success",
+			resp: []Account{
+				{
+					URL:         "my.1password.com",
+					Email:       "foo@example.com",
+					UserUUID:    "F7GSLUVENFGZVF2HVACL3IAS7F",
+					AccountUUID: "VZSYVT2LGHTBWBQGUJAIZVRABM",
+				},
+			},
+		},
+		{
+			name: "Empty",
+			resp: []Account{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t)}
+			resp, err := cli.ListAccounts()
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestWhoAmI(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *Account
+		err  string
+	}{
+		{
+			name: "Success",
+			resp: &Account{
+				URL:         "https://my.1password.com",
+				Email:       "foo@example.com",
+				UserUUID:    "F7GSLUVENFGZVF2HVACL3IAS7F",
+				AccountUUID: "VZSYVT2LGHTBWBQGUJAIZVRABM",
+				UserType:    "HUMAN",
+			},
+		},
+		{
+			name: "SignedOut",
+			err:  "exit status 1: [ERROR] 2022/04/20 09:41:00 account is not signed in\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t)}
+			resp, err := cli.WhoAmI()
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
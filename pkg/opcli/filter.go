@@ -7,6 +7,15 @@ import (
 // Filter represents a filter flag passed to 1Password CLI some commands.
 type Filter func() []string
 
+// GeneratePassword gives the created item a password generated according to the recipe.
+// The recipe is always passed explicitly, filling in DefaultRecipe for the zero value,
+// so that the password never depends on the defaults of the installed op version.
+func GeneratePassword(recipe Recipe) Filter {
+	return func() []string {
+		return []string{"--generate-password=" + recipe.orDefault().String()}
+	}
+}
+
 // IncludeArchive expands item list to include items in the Archive.
 func IncludeArchive() Filter {
 	return func() []string {
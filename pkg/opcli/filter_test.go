@@ -6,6 +6,12 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestGeneratePassword(t *testing.T) {
+	assert.Equal(t, []string{"--generate-password=letters,digits,symbols,32"}, GeneratePassword(Recipe{})())
+	assert.Equal(t, []string{"--generate-password=digits,32"}, GeneratePassword(Recipe{Digits: true})())
+	assert.Equal(t, []string{"--generate-password=letters,digits,40"}, GeneratePassword(Recipe{Length: 40, Letters: true, Digits: true})())
+}
+
 func TestIncludeArchive(t *testing.T) {
 	assert.Equal(t, []string{"--include-archive"}, IncludeArchive()())
 }
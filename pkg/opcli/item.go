@@ -1,6 +1,9 @@
 package opcli
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -22,6 +25,9 @@ type Item struct {
 	Sections []Section `json:"sections"`
 	Fields   []Field   `json:"fields"`
 	Files    []File    `json:"files"`
+
+	// Scope is the ID of the section the item was scoped to with InSection; it's not part of the item in 1Password.
+	Scope string `json:"-"`
 }
 
 func (i Item) Field(name string) *Field {
@@ -35,6 +41,43 @@ func (i Item) Field(name string) *Field {
 	return f
 }
 
+// SectionField returns the field with given ID or label within the section with given ID or label.
+func (i Item) SectionField(section, name string) *Field {
+	for _, field := range i.Fields {
+		if (field.Section.ID == section || field.Section.Label == section) && (field.ID == name || field.Label == name) {
+			return &field
+		}
+	}
+	return nil
+}
+
+// SectionFields returns the fields within the section with given ID or label.
+// Fields outside of any section are returned for an empty section.
+func (i Item) SectionFields(section string) []Field {
+	return i.FindFields(func(f Field) bool {
+		if section == "" {
+			return f.Section.ID == ""
+		}
+		return f.Section.ID == section || f.Section.Label == section
+	})
+}
+
+// InSection returns a copy of the item scoped to the section with given ID or label, or nil if there is no such section.
+// The fields of the section come first, followed by the fields outside of any section shared by all sections.
+func (i Item) InSection(section string) *Item {
+	for _, s := range i.Sections {
+		if s.ID != section && s.Label != section {
+			continue
+		}
+		c := i
+		c.Scope = s.ID
+		c.Sections = []Section{s}
+		c.Fields = append(i.SectionFields(s.ID), i.SectionFields("")...)
+		return &c
+	}
+	return nil
+}
+
 func (i Item) FindFields(matching func(f Field) bool) []Field {
 	var f []Field
 	for _, field := range i.Fields {
@@ -71,6 +114,68 @@ const (
 	CategoryWirelessRouter       = "Wireless Router"
 )
 
+// ParseCategory parses a category from either its name (e.g., API Credential) or its identifier (e.g., API_CREDENTIAL).
+func ParseCategory(s string) (Category, error) {
+	var c Category
+	id := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), " ", "_"))
+	if err := c.UnmarshalText([]byte(id)); err != nil {
+		return c, err
+	}
+	if c == "" {
+		return c, fmt.Errorf("unknown category: %q", s)
+	}
+	return c, nil
+}
+
+func (c Category) MarshalText() ([]byte, error) {
+	switch c {
+	case CategoryAPICredential:
+		return []byte("API_CREDENTIAL"), nil
+	case CategoryBankAccount:
+		return []byte("BANK_ACCOUNT"), nil
+	case CategoryCreditCard:
+		return []byte("CREDIT_CARD"), nil
+	case CategoryDatabase:
+		return []byte("DATABASE"), nil
+	case CategoryDocument:
+		return []byte("DOCUMENT"), nil
+	case CategoryDriverLicense:
+		return []byte("DRIVER_LICENSE"), nil
+	case CategoryEmailAccount:
+		return []byte("EMAIL_ACCOUNT"), nil
+	case CategoryIdentity:
+		return []byte("IDENTITY"), nil
+	case CategoryLogin:
+		return []byte("LOGIN"), nil
+	case CategoryMedicalRecord:
+		return []byte("MEDICAL_RECORD"), nil
+	case CategoryMembership:
+		return []byte("MEMBERSHIP"), nil
+	case CategoryOutdoorLicense:
+		return []byte("OUTDOOR_LICENSE"), nil
+	case CategoryPassport:
+		return []byte("PASSPORT"), nil
+	case CategoryPassword:
+		return []byte("PASSWORD"), nil
+	case CategoryRewardProgram:
+		return []byte("REWARD_PROGRAM"), nil
+	case CategorySecureNote:
+		return []byte("SECURE_NOTE"), nil
+	case CategoryServer:
+		return []byte("SERVER"), nil
+	case CategorySocialSecurityNumber:
+		return []byte("SOCIAL_SECURITY_NUMBER"), nil
+	case CategorySoftwareLicense:
+		return []byte("SOFTWARE_LICENSE"), nil
+	case CategorySSHKey:
+		return []byte("SSH_KEY"), nil
+	case CategoryWirelessRouter:
+		return []byte("WIRELESS_ROUTER"), nil
+	default:
+		return []byte(c), nil
+	}
+}
+
 func (c *Category) UnmarshalText(text []byte) error {
 	switch string(text) {
 	case "API_CREDENTIAL":
@@ -174,6 +279,45 @@ const (
 	FieldTypeURL              = "URL"
 )
 
+func (f FieldType) MarshalText() ([]byte, error) {
+	switch f {
+	case FieldTypeAddress:
+		return []byte("ADDRESS"), nil
+	case FieldTypeConcealed:
+		return []byte("CONCEALED"), nil
+	case FieldTypeCreditCardNumber:
+		return []byte("CREDIT_CARD_NUMBER"), nil
+	case FieldTypeCreditCardType:
+		return []byte("CREDIT_CARD_TYPE"), nil
+	case FieldTypeDate:
+		return []byte("DATE"), nil
+	case FieldTypeEmail:
+		return []byte("EMAIL"), nil
+	case FieldTypeFile:
+		return []byte("FILE"), nil
+	case FieldTypeGender:
+		return []byte("GENDER"), nil
+	case FieldTypeMenu:
+		return []byte("MENU"), nil
+	case FieldTypeMonthYear:
+		return []byte("MONTH_YEAR"), nil
+	case FieldTypeOTP:
+		return []byte("OTP"), nil
+	case FieldTypePhone:
+		return []byte("PHONE"), nil
+	case FieldTypeReference:
+		return []byte("REFERENCE"), nil
+	case FieldTypeSSHKey:
+		return []byte("SSHKEY"), nil
+	case FieldTypeString:
+		return []byte("STRING"), nil
+	case FieldTypeURL:
+		return []byte("URL"), nil
+	default:
+		return []byte("UNKNOWN"), nil
+	}
+}
+
 func (f *FieldType) UnmarshalText(text []byte) error {
 	switch string(text) {
 	case "ADDRESS":
@@ -267,11 +411,6 @@ type URL struct {
 	HRef    string `json:"href"`
 }
 
-type Vault struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
-}
-
 // ListItems returns a list of all items the account has read access to.
 // Excludes items in the Archive by default.
 //
@@ -284,16 +423,93 @@ type Vault struct {
 //   - WithVault()            Only list items in this vault.
 func (c *CLI) ListItems(filters ...Filter) ([]Item, error) {
 	var val []Item
-	err := c.execJSON(applyFilters([]string{"item", "list"}, filters), nil, &val)
+	err := c.execJSON(applyFilters([]string{"item", "list"}, filters), nil, nil, &val)
 	return val, err
 }
 
 // CreateItem creates a new item and returns it with all the fileds like ID filled.
 //
-//	--dry-run                      Perform a dry run of the command and output a preview of the resulting item.
-//	--generate-password[=recipe]   Give the item a randomly generated password.
-func (c *CLI) CreateItem(item *Item) (*Item, error) {
-	return nil, nil
+// Only the title, category, tags, URLs, sections and fields of the given item are used.
+// The item is passed to 1Password CLI on stdin, so secret values never show up in the process arguments.
+//
+// Supported filters:
+//
+//   - GeneratePassword()     Give the item a randomly generated password.
+//   - WithVault()            Save the item in this vault.
+func (c *CLI) CreateItem(item *Item, filters ...Filter) (*Item, error) {
+	b, err := json.Marshal(newItemTemplate(item))
+	if err != nil {
+		return nil, err
+	}
+	var val *Item
+	err = c.execJSON(applyFilters([]string{"item", "create"}, filters), nil, b, &val)
+	return val, err
+}
+
+// EditItem replaces the title, tags, URLs, sections and fields of the item with given ID, and returns the updated item.
+//
+// Like CreateItem, the item is passed to 1Password CLI on stdin. Fields keep their IDs, so 1Password records
+// the previous values in the item history (and the password history for passwords).
+//
+// Supported filters:
+//
+//   - WithVault()            Look up the item in this vault.
+func (c *CLI) EditItem(item *Item, filters ...Filter) (*Item, error) {
+	if item.ID == "" {
+		return nil, fmt.Errorf("item %q has no ID", item.Title)
+	}
+	b, err := json.Marshal(newItemTemplate(item))
+	if err != nil {
+		return nil, err
+	}
+	var val *Item
+	err = c.execJSON(applyFilters([]string{"item", "edit", item.ID}, filters), nil, b, &val)
+	return val, err
+}
+
+// newItemTemplate returns the template for the item accepted by `op item create` and `op item edit`.
+func newItemTemplate(item *Item) itemTemplate {
+	tmpl := itemTemplate{
+		Title:    item.Title,
+		Category: item.Category,
+		Tags:     item.Tags,
+		URLs:     item.URLs,
+		Sections: item.Sections,
+	}
+	for _, f := range item.Fields {
+		ft := fieldTemplate{
+			ID:      f.ID,
+			Type:    f.Type,
+			Purpose: f.Purpose,
+			Label:   f.Label,
+			Value:   f.Value,
+		}
+		if f.Section.ID != "" {
+			section := f.Section
+			ft.Section = &section
+		}
+		tmpl.Fields = append(tmpl.Fields, ft)
+	}
+	return tmpl
+}
+
+// itemTemplate is the subset of item properties accepted by `op item create` and `op item edit`.
+type itemTemplate struct {
+	Title    string          `json:"title"`
+	Category Category        `json:"category"`
+	Tags     []string        `json:"tags,omitempty"`
+	URLs     []URL           `json:"urls,omitempty"`
+	Sections []Section       `json:"sections,omitempty"`
+	Fields   []fieldTemplate `json:"fields,omitempty"`
+}
+
+type fieldTemplate struct {
+	ID      string       `json:"id,omitempty"`
+	Section *Section     `json:"section,omitempty"`
+	Type    FieldType    `json:"type"`
+	Purpose FieldPurpose `json:"purpose,omitempty"`
+	Label   string       `json:"label,omitempty"`
+	Value   string       `json:"value"`
 }
 
 // GetItem returns the details of an item specified by its name, ID, or sharing link.
@@ -304,7 +520,7 @@ func (c *CLI) CreateItem(item *Item) (*Item, error) {
 //   - WithVault()            Only list items in this vault.
 func (c *CLI) GetItem(name string, filters ...Filter) (*Item, error) {
 	var val *Item
-	err := c.execJSON(applyFilters([]string{"item", "get", name}, filters), nil, &val)
+	err := c.execJSON(applyFilters([]string{"item", "get", name}, filters), nil, nil, &val)
 	return val, err
 }
 
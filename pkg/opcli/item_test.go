@@ -1,6 +1,8 @@
 package opcli
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -112,6 +114,102 @@ func TestItemField(t *testing.T) {
 	}
 }
 
+func TestItemSectionField(t *testing.T) {
+	item := Item{
+		Fields: []Field{
+			{
+				ID:    "username",
+				Type:  FieldTypeString,
+				Label: "username",
+				Value: "foo",
+			},
+			{
+				ID:      "Section_FFD16B98A713452695E49DA0EB32BFD0.username",
+				Section: Section{ID: "Section_FFD16B98A713452695E49DA0EB32BFD0", Label: "GitHub"},
+				Type:    FieldTypeString,
+				Label:   "username",
+				Value:   "bar",
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		section string
+		key     string
+		result  *Field
+	}{
+		{
+			name:    "MatchSectionLabel",
+			section: "GitHub",
+			key:     "username",
+			result:  &item.Fields[1],
+		},
+		{
+			name:    "MatchSectionID",
+			section: "Section_FFD16B98A713452695E49DA0EB32BFD0",
+			key:     "username",
+			result:  &item.Fields[1],
+		},
+		{
+			name:    "NoMatchSection",
+			section: "GitLab",
+			key:     "username",
+		},
+		{
+			name:    "NoMatchField",
+			section: "GitHub",
+			key:     "password",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.result, item.SectionField(test.section, test.key))
+		})
+	}
+}
+
+func TestItemSectionFields(t *testing.T) {
+	item := Item{
+		Sections: []Section{{ID: "Section_1", Label: "Acme"}},
+		Fields: []Field{
+			{ID: "username", Label: "username", Value: "foo"},
+			{ID: "h2xm", Section: Section{ID: "Section_1", Label: "Acme"}, Label: "credential", Value: "bar"},
+		},
+	}
+	assert.Equal(t, []Field{item.Fields[0]}, item.SectionFields(""))
+	assert.Equal(t, []Field{item.Fields[1]}, item.SectionFields("Acme"))
+	assert.Equal(t, []Field{item.Fields[1]}, item.SectionFields("Section_1"))
+	assert.Nil(t, item.SectionFields("Other"))
+}
+
+func TestItemInSection(t *testing.T) {
+	item := Item{
+		ID:    "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title: "GitHub",
+		Sections: []Section{
+			{ID: "Section_1", Label: "Acme"},
+			{ID: "Section_2", Label: "Initech"},
+		},
+		Fields: []Field{
+			{ID: "username", Label: "username", Value: "foo"},
+			{ID: "h2xm", Section: Section{ID: "Section_1", Label: "Acme"}, Label: "credential", Value: "bar"},
+			{ID: "o5ry", Section: Section{ID: "Section_2", Label: "Initech"}, Label: "credential", Value: "baz"},
+		},
+	}
+	assert.Equal(t, &Item{
+		ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+		Title:    "GitHub",
+		Sections: []Section{{ID: "Section_2", Label: "Initech"}},
+		Scope:    "Section_2",
+		Fields: []Field{
+			{ID: "o5ry", Section: Section{ID: "Section_2", Label: "Initech"}, Label: "credential", Value: "baz"},
+			{ID: "username", Label: "username", Value: "foo"},
+		},
+	}, item.InSection("Initech"))
+	assert.Equal(t, "bar", item.InSection("Section_1").Field("credential").Value)
+	assert.Nil(t, item.InSection("Other"))
+}
+
 func TestItemFindFields(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -340,6 +438,145 @@ func TestCategoryUnmarshalText(t *testing.T) {
 	}
 }
 
+func TestParseCategory(t *testing.T) {
+	tests := []struct {
+		value  string
+		result Category
+		err    string
+	}{
+		{
+			value:  "API Credential",
+			result: CategoryAPICredential,
+		},
+		{
+			value:  "api credential",
+			result: CategoryAPICredential,
+		},
+		{
+			value:  "API_CREDENTIAL",
+			result: CategoryAPICredential,
+		},
+		{
+			value:  "Login",
+			result: CategoryLogin,
+		},
+		{
+			value: "Foo",
+			err:   `unknown category: "Foo"`,
+		},
+	}
+	for _, test := range tests {
+		c, err := ParseCategory(test.value)
+		if test.err == "" {
+			assert.NoError(t, err)
+			assert.Equal(t, test.result, c)
+		} else {
+			assert.EqualError(t, err, test.err)
+		}
+	}
+}
+
+func TestCategoryMarshalText(t *testing.T) {
+	tests := []struct {
+		value  Category
+		result string
+	}{
+		{
+			value:  CategoryAPICredential,
+			result: "API_CREDENTIAL",
+		},
+		{
+			value:  CategoryBankAccount,
+			result: "BANK_ACCOUNT",
+		},
+		{
+			value:  CategoryCreditCard,
+			result: "CREDIT_CARD",
+		},
+		{
+			value:  CategoryDatabase,
+			result: "DATABASE",
+		},
+		{
+			value:  CategoryDocument,
+			result: "DOCUMENT",
+		},
+		{
+			value:  CategoryDriverLicense,
+			result: "DRIVER_LICENSE",
+		},
+		{
+			value:  CategoryEmailAccount,
+			result: "EMAIL_ACCOUNT",
+		},
+		{
+			value:  CategoryIdentity,
+			result: "IDENTITY",
+		},
+		{
+			value:  CategoryLogin,
+			result: "LOGIN",
+		},
+		{
+			value:  CategoryMedicalRecord,
+			result: "MEDICAL_RECORD",
+		},
+		{
+			value:  CategoryMembership,
+			result: "MEMBERSHIP",
+		},
+		{
+			value:  CategoryOutdoorLicense,
+			result: "OUTDOOR_LICENSE",
+		},
+		{
+			value:  CategoryPassport,
+			result: "PASSPORT",
+		},
+		{
+			value:  CategoryPassword,
+			result: "PASSWORD",
+		},
+		{
+			value:  CategoryRewardProgram,
+			result: "REWARD_PROGRAM",
+		},
+		{
+			value:  CategorySecureNote,
+			result: "SECURE_NOTE",
+		},
+		{
+			value:  CategoryServer,
+			result: "SERVER",
+		},
+		{
+			value:  CategorySocialSecurityNumber,
+			result: "SOCIAL_SECURITY_NUMBER",
+		},
+		{
+			value:  CategorySoftwareLicense,
+			result: "SOFTWARE_LICENSE",
+		},
+		{
+			value:  CategorySSHKey,
+			result: "SSH_KEY",
+		},
+		{
+			value:  CategoryWirelessRouter,
+			result: "WIRELESS_ROUTER",
+		},
+		{
+			value:  Category("Custom"),
+			result: "Custom",
+		},
+	}
+	for _, test := range tests {
+		b, err := test.value.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, test.result, string(b))
+	}
+}
+
 func TestFieldTypeUnmarshalText(t *testing.T) {
 	tests := []struct {
 		text   string
@@ -426,6 +663,87 @@ func TestFieldTypeUnmarshalText(t *testing.T) {
 	}
 }
 
+func TestFieldTypeMarshalText(t *testing.T) {
+	tests := []struct {
+		value  FieldType
+		result string
+	}{
+		{
+			value:  FieldTypeAddress,
+			result: "ADDRESS",
+		},
+		{
+			value:  FieldTypeConcealed,
+			result: "CONCEALED",
+		},
+		{
+			value:  FieldTypeCreditCardNumber,
+			result: "CREDIT_CARD_NUMBER",
+		},
+		{
+			value:  FieldTypeCreditCardType,
+			result: "CREDIT_CARD_TYPE",
+		},
+		{
+			value:  FieldTypeDate,
+			result: "DATE",
+		},
+		{
+			value:  FieldTypeEmail,
+			result: "EMAIL",
+		},
+		{
+			value:  FieldTypeFile,
+			result: "FILE",
+		},
+		{
+			value:  FieldTypeGender,
+			result: "GENDER",
+		},
+		{
+			value:  FieldTypeMenu,
+			result: "MENU",
+		},
+		{
+			value:  FieldTypeMonthYear,
+			result: "MONTH_YEAR",
+		},
+		{
+			value:  FieldTypeOTP,
+			result: "OTP",
+		},
+		{
+			value:  FieldTypePhone,
+			result: "PHONE",
+		},
+		{
+			value:  FieldTypeReference,
+			result: "REFERENCE",
+		},
+		{
+			value:  FieldTypeSSHKey,
+			result: "SSHKEY",
+		},
+		{
+			value:  FieldTypeString,
+			result: "STRING",
+		},
+		{
+			value:  FieldTypeURL,
+			result: "URL",
+		},
+		{
+			value:  FieldTypeUnknown,
+			result: "UNKNOWN",
+		},
+	}
+	for _, test := range tests {
+		b, err := test.value.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, test.result, string(b))
+	}
+}
+
 func TestListItems(t *testing.T) {
 	tests := []struct {
 		name string
@@ -661,7 +979,115 @@ func TestListItems(t *testing.T) {
 }
 
 func TestCreateItem(t *testing.T) {
-	// FIXME: implement
+	tests := []struct {
+		name  string
+		item  *Item
+		vault string
+		stdin string
+		resp  *Item
+		err   string
+	}{
+		{
+			name: "Success",
+			item: &Item{
+				ID:       "ignored",
+				Title:    "foo.com",
+				Category: CategoryAPICredential,
+				Sections: []Section{
+					{
+						ID:    "git",
+						Label: "git",
+					},
+				},
+				Fields: []Field{
+					{
+						ID:    "username",
+						Type:  FieldTypeString,
+						Label: "username",
+						Value: "qux",
+					},
+					{
+						ID:    "credential",
+						Type:  FieldTypeConcealed,
+						Label: "credential",
+						Value: "wat",
+					},
+					{
+						Section: Section{
+							ID:    "git",
+							Label: "git",
+						},
+						Type:  FieldTypeString,
+						Label: "path",
+						Value: "bar/baz.git",
+					},
+				},
+			},
+			vault: "Personal",
+			stdin: `{"title":"foo.com","category":"API_CREDENTIAL","sections":[{"id":"git","label":"git"}],"fields":[{"id":"username","type":"STRING","label":"username","value":"qux"},{"id":"credential","type":"CONCEALED","label":"credential","value":"wat"},{"section":{"id":"git","label":"git"},"type":"STRING","label":"path","value":"bar/baz.git"}]}`,
+			resp: &Item{
+				ID:      "kpbhk2zfw6m4pgdwylbbpmkcke",
+				Title:   "foo.com",
+				Version: 1,
+				Vault: Vault{
+					ID:   "ynghx4vwntpezvhqyeglcp7v7f",
+					Name: "Personal",
+				},
+				Category:     CategoryAPICredential,
+				LastEditedBy: "F7GSLUVENFGZVF2HVACL3IAS7F",
+				CreatedAt:    time.Date(2022, time.April, 20, 9, 41, 0, 0, time.UTC),
+				UpdatedAt:    time.Date(2022, time.April, 20, 9, 41, 0, 0, time.UTC),
+				Sections: []Section{
+					{
+						ID:    "git",
+						Label: "git",
+					},
+				},
+				Fields: []Field{
+					{
+						ID:        "username",
+						Type:      FieldTypeString,
+						Label:     "username",
+						Value:     "qux",
+						Reference: "op://Personal/foo.com/username",
+					},
+					{
+						ID:        "credential",
+						Type:      FieldTypeConcealed,
+						Label:     "credential",
+						Value:     "wat",
+						Reference: "op://Personal/foo.com/credential",
+					},
+					{
+						ID: "4t5ba2cmhc3yvxqyqyn6ajvgsq",
+						Section: Section{
+							ID:    "git",
+							Label: "git",
+						},
+						Type:      FieldTypeString,
+						Label:     "path",
+						Value:     "bar/baz.git",
+						Reference: "op://Personal/foo.com/git/path",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t)}
+			resp, err := cli.CreateItem(test.item, WithVault(test.vault))
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+			stdin, err := os.ReadFile(filepath.Join(filepath.Dir(cli.Path), "op_stdin"))
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.stdin, string(stdin))
+		})
+	}
 }
 
 func TestGetItem(t *testing.T) {
@@ -802,3 +1228,90 @@ func TestArchiveItem(t *testing.T) {
 		})
 	}
 }
+
+func TestEditItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  *Item
+		vault string
+		stdin string
+		resp  *Item
+		err   string
+	}{
+		{
+			name: "Success",
+			item: &Item{
+				ID:       "kpbhk2zfw6m4pgdwylbbpmkcke",
+				Title:    "foo.com",
+				Version:  1,
+				Category: CategoryAPICredential,
+				Fields: []Field{
+					{
+						ID:    "username",
+						Type:  FieldTypeString,
+						Label: "username",
+						Value: "qux",
+					},
+					{
+						ID:    "credential",
+						Type:  FieldTypeConcealed,
+						Label: "credential",
+						Value: "new",
+					},
+				},
+			},
+			vault: "Personal",
+			stdin: `{"title":"foo.com","category":"API_CREDENTIAL","fields":[{"id":"username","type":"STRING","label":"username","value":"qux"},{"id":"credential","type":"CONCEALED","label":"credential","value":"new"}]}`,
+			resp: &Item{
+				ID:      "kpbhk2zfw6m4pgdwylbbpmkcke",
+				Title:   "foo.com",
+				Version: 2,
+				Vault: Vault{
+					ID:   "ynghx4vwntpezvhqyeglcp7v7f",
+					Name: "Personal",
+				},
+				Category:     CategoryAPICredential,
+				LastEditedBy: "F7GSLUVENFGZVF2HVACL3IAS7F",
+				CreatedAt:    time.Date(2022, time.April, 20, 9, 41, 0, 0, time.UTC),
+				UpdatedAt:    time.Date(2022, time.May, 2, 14, 3, 0, 0, time.UTC),
+				Fields: []Field{
+					{
+						ID:        "username",
+						Type:      FieldTypeString,
+						Label:     "username",
+						Value:     "qux",
+						Reference: "op://Personal/foo.com/username",
+					},
+					{
+						ID:        "credential",
+						Type:      FieldTypeConcealed,
+						Label:     "credential",
+						Value:     "new",
+						Reference: "op://Personal/foo.com/credential",
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t)}
+			resp, err := cli.EditItem(test.item, WithVault(test.vault))
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+			stdin, err := os.ReadFile(filepath.Join(filepath.Dir(cli.Path), "op_stdin"))
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.stdin, string(stdin))
+		})
+	}
+}
+
+func TestEditItemWithoutID(t *testing.T) {
+	cli := &CLI{Path: "/nonexistent/op"}
+	_, err := cli.EditItem(&Item{Title: "foo.com"})
+	assert.EqualError(t, err, `item "foo.com" has no ID`)
+}
@@ -1,11 +1,14 @@
 package opcli
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // CLI represents the 1Password CLI.
@@ -20,15 +23,43 @@ type CLI struct {
 	// Path specifies the absolute path to the 1Password CLI executable.
 	// When not set (default), exec.LookPath() will be utilized to find the `op` executable on $PATH.
 	Path string
+
+	// Installed is the version of 1Password CLI found by Detect, used to gate features.
+	// When not set (default), the latest features are used.
+	Installed *Version
+
+	// Logger traces executed commands at the debug level when set.
+	// Only the command arguments, duration, and errors are logged; never data passed on stdin or the output.
+	Logger *slog.Logger
 }
 
 // Version returns 1Password CLI version.
-func (c CLI) Version() (string, error) {
+func (c CLI) Version() (Version, error) {
 	b, err := c.execRaw([]string{"--version"}, nil)
-	return strings.TrimSpace(string(b)), err
+	if err != nil {
+		return Version{}, err
+	}
+	return ParseVersion(string(b))
 }
 
 func (c CLI) execRaw(cmd []string, args []string) ([]byte, error) {
+	return c.execInput(cmd, args, nil)
+}
+
+// Executable returns the path of the 1Password CLI executable: Path when set, or the `op` executable found on $PATH.
+func (c CLI) Executable() (string, error) {
+	if c.Path != "" {
+		return c.Path, nil
+	}
+	p, err := exec.LookPath("op")
+	if err != nil && !errors.Is(err, exec.ErrDot) {
+		return "", err
+	}
+	return p, nil
+}
+
+// execInput executes the command with given data passed on stdin.
+func (c CLI) execInput(cmd []string, args []string, stdin []byte) ([]byte, error) {
 	if c.Account != "" {
 		cmd = append(cmd, fmt.Sprintf("--account=%s", c.Account))
 	}
@@ -37,20 +68,21 @@ func (c CLI) execRaw(cmd []string, args []string) ([]byte, error) {
 	}
 	cmd = append(cmd, args...)
 
-	path := c.Path
-	if path == "" {
-		p, err := exec.LookPath("op")
-		if err != nil && !errors.Is(err, exec.ErrDot) {
-			return nil, err
-		}
-		path = p
+	path, err := c.Executable()
+	if err != nil {
+		return nil, err
 	}
 
 	op := &exec.Cmd{
 		Path: path,
 		Args: append([]string{path}, cmd...),
 	}
+	if stdin != nil {
+		op.Stdin = bytes.NewReader(stdin)
+	}
+	start := time.Now()
 	b, err := op.Output()
+	c.trace(cmd, time.Since(start), err)
 	if err != nil {
 		if ee, ok := err.(*exec.ExitError); ok {
 			return nil, fmt.Errorf("%s: %s", ee, ee.Stderr)
@@ -60,12 +92,29 @@ func (c CLI) execRaw(cmd []string, args []string) ([]byte, error) {
 	return b, err
 }
 
-func (c CLI) execJSON(cmd []string, args []string, v any) error {
-	cmd = append(cmd, "--format", "json", "--iso-timestamps")
-	b, err := c.execRaw(cmd, args)
+func (c CLI) execJSON(cmd []string, args []string, stdin []byte, v any) error {
+	cmd = append(cmd, "--format", "json")
+	if c.Supports(FeatureISOTimestamps) {
+		cmd = append(cmd, "--iso-timestamps")
+	}
+	b, err := c.execInput(cmd, args, stdin)
 	if err != nil {
 		return err
 	}
 	err = json.Unmarshal(b, &v)
 	return err
 }
+
+// trace logs the executed command.
+func (c CLI) trace(cmd []string, d time.Duration, err error) {
+	if c.Logger == nil {
+		return
+	}
+	attrs := []any{slog.String("args", strings.Join(cmd, " ")), slog.Duration("duration", d)}
+	if ee, ok := err.(*exec.ExitError); ok {
+		attrs = append(attrs, slog.Int("status", ee.ExitCode()), slog.String("stderr", strings.TrimSpace(string(ee.Stderr))))
+	} else if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	c.Logger.Debug("op", attrs...)
+}
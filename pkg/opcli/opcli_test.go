@@ -1,7 +1,9 @@
 package opcli
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -40,7 +42,7 @@ func TestVersion(t *testing.T) {
 	tests := []struct {
 		name string
 		call func(cli *CLI) (any, error)
-		resp string
+		resp any
 		err  string
 	}{
 		{
@@ -48,7 +50,7 @@ func TestVersion(t *testing.T) {
 			call: func(cli *CLI) (any, error) {
 				return cli.Version()
 			},
-			resp: `2.7.0`,
+			resp: Version{Major: 2, Minor: 7},
 		},
 		{
 			name: "ExecNotFound",
@@ -72,3 +74,44 @@ func TestVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		name   string
+		call   func(cli *CLI) error
+		expect []string
+	}{
+		{
+			name: "Success",
+			call: func(cli *CLI) error {
+				_, err := cli.Version()
+				return err
+			},
+			expect: []string{"level=DEBUG msg=op args=--version duration="},
+		},
+		{
+			name: "Failure",
+			call: func(cli *CLI) error {
+				_, err := cli.GetItem("Missing")
+				return err
+			},
+			expect: []string{
+				`msg=op args="item get Missing --format json --iso-timestamps" duration=`,
+				`status=1 stderr="[ERROR] 2022/04/20 09:41:00 \"Missing\" isn't an item."`,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			cli := &CLI{
+				Path:   mockOp(t),
+				Logger: slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			}
+			_ = test.call(cli)
+			for _, v := range test.expect {
+				assert.Contains(t, b.String(), v)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package opcli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Read returns the value of a secret specified by its secret reference (e.g. op://vault/item/field).
+// See https://developer.1password.com/docs/cli/secret-references for more details.
+func (c *CLI) Read(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "op://") {
+		return "", fmt.Errorf("invalid secret reference: %q", ref)
+	}
+	b, err := c.execRaw([]string{"read", ref}, nil)
+	return strings.TrimSuffix(string(b), "\n"), err
+}
@@ -0,0 +1,52 @@
+package opcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(cli *CLI) (any, error)
+		resp string
+		err  string
+
+		// invalid tests fail before running op, so they have no fixture
+		invalid bool
+	}{
+		{
+			name: "Success",
+			call: func(cli *CLI) (any, error) { return cli.Read("op://Personal/Foo/add more/username") },
+			resp: "wat",
+		},
+		{
+			name: "NotFound",
+			call: func(cli *CLI) (any, error) { return cli.Read("op://Personal/Foo/bar") },
+			err:  "exit status 1: [ERROR] 2022/04/20 09:41:00 could not read secret op://Personal/Foo/bar: could not find field bar\n",
+		},
+		{
+			name: "InvalidReference",
+			call: func(cli *CLI) (any, error) { return cli.Read("Personal/Foo/bar") },
+			err:  `invalid secret reference: "Personal/Foo/bar"`,
+
+			invalid: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: "/nonexistent/op"}
+			if !test.invalid {
+				cli.Path = mockOp(t)
+			}
+			resp, err := test.call(cli)
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package opcli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultRecipe is the default recipe of 1Password CLI.
+var DefaultRecipe = Recipe{Length: 32, Letters: true, Digits: true, Symbols: true}
+
+// Recipe describes a password 1Password generates: its length and the character sets used.
+// The zero value stands for DefaultRecipe.
+type Recipe struct {
+	// Length is the number of characters, between 1 and 64.
+	Length int
+
+	Letters bool
+	Digits  bool
+	Symbols bool
+}
+
+// ParseRecipe parses a recipe in the format used by 1Password CLI, e.g. letters,digits,32.
+func ParseRecipe(s string) (Recipe, error) {
+	var r Recipe
+	if strings.TrimSpace(s) == "" {
+		return r, nil
+	}
+	for _, v := range strings.Split(s, ",") {
+		switch v = strings.ToLower(strings.TrimSpace(v)); v {
+		case "letters":
+			r.Letters = true
+		case "digits":
+			r.Digits = true
+		case "symbols":
+			r.Symbols = true
+		default:
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 64 {
+				return Recipe{}, fmt.Errorf("invalid recipe %q: expected character sets (letters, digits, symbols) and a length between 1 and 64", s)
+			}
+			r.Length = n
+		}
+	}
+	if !r.Letters && !r.Digits && !r.Symbols {
+		return Recipe{}, fmt.Errorf("invalid recipe %q: expected at least one character set (letters, digits, symbols)", s)
+	}
+	return r, nil
+}
+
+// String returns the recipe in the format used by 1Password CLI, or an empty string for the default recipe.
+func (r Recipe) String() string {
+	var s []string
+	if r.Letters {
+		s = append(s, "letters")
+	}
+	if r.Digits {
+		s = append(s, "digits")
+	}
+	if r.Symbols {
+		s = append(s, "symbols")
+	}
+	if r.Length > 0 {
+		s = append(s, strconv.Itoa(r.Length))
+	}
+	return strings.Join(s, ",")
+}
+
+// orDefault returns the recipe with the length and character sets of DefaultRecipe filled in when unset.
+func (r Recipe) orDefault() Recipe {
+	if r.Length == 0 {
+		r.Length = DefaultRecipe.Length
+	}
+	if !r.Letters && !r.Digits && !r.Symbols {
+		r.Letters, r.Digits, r.Symbols = DefaultRecipe.Letters, DefaultRecipe.Digits, DefaultRecipe.Symbols
+	}
+	return r
+}
@@ -0,0 +1,61 @@
+package opcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecipe(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		resp Recipe
+		err  string
+	}{
+		{
+			name: "Default",
+		},
+		{
+			name: "Full",
+			in:   "letters,digits,symbols,32",
+			resp: Recipe{Length: 32, Letters: true, Digits: true, Symbols: true},
+		},
+		{
+			name: "WithoutLength",
+			in:   "Letters, Digits",
+			resp: Recipe{Letters: true, Digits: true},
+		},
+		{
+			name: "TooLong",
+			in:   "letters,65",
+			err:  `invalid recipe "letters,65": expected character sets (letters, digits, symbols) and a length between 1 and 64`,
+		},
+		{
+			name: "UnknownCharacterSet",
+			in:   "emoji,20",
+			err:  `invalid recipe "emoji,20": expected character sets (letters, digits, symbols) and a length between 1 and 64`,
+		},
+		{
+			name: "NoCharacterSet",
+			in:   "20",
+			err:  `invalid recipe "20": expected at least one character set (letters, digits, symbols)`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := ParseRecipe(test.in)
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestRecipeString(t *testing.T) {
+	assert.Equal(t, "", Recipe{}.String())
+	assert.Equal(t, "letters,symbols,20", Recipe{Length: 20, Letters: true, Symbols: true}.String())
+}
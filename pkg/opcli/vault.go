@@ -0,0 +1,13 @@
+package opcli
+
+type Vault struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetVault returns the details of a vault specified by its name or ID.
+func (c *CLI) GetVault(name string) (*Vault, error) {
+	var val *Vault
+	err := c.execJSON([]string{"vault", "get", name}, nil, nil, &val)
+	return val, err
+}
@@ -0,0 +1,39 @@
+package opcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVault(t *testing.T) {
+	tests := []struct {
+		name  string
+		vault string
+		resp  *Vault
+		err   string
+	}{
+		{
+			name:  "Success",
+			vault: "Personal",
+			resp:  &Vault{ID: "ynghx4vwntpezvhqyeglcp7v7f", Name: "Personal"},
+		},
+		{
+			name:  "NotFound",
+			vault: "Team",
+			err:   "exit status 1: [ERROR] 2022/04/20 09:41:00 \"Team\" isn't a vault in this account. Specify the vault with its ID or name.\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t)}
+			resp, err := cli.GetVault(test.vault)
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package opcli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinVersion is the oldest supported 1Password CLI version; earlier versions use a different command syntax.
+var MinVersion = Version{Major: 2}
+
+// ErrUnsupportedVersion is returned when the installed 1Password CLI is older than MinVersion.
+var ErrUnsupportedVersion = errors.New("unsupported op version")
+
+// Version is a semantic version of 1Password CLI, like 2.24.0 or 2.25.0-beta.01.
+type Version struct {
+	Major, Minor, Patch int
+
+	// Pre is the pre-release suffix without the leading dash, if any.
+	Pre string
+}
+
+// ParseVersion parses a semantic version, with an optional leading "v".
+func ParseVersion(s string) (Version, error) {
+	var v Version
+	core, pre, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(s), "v"), "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+	for i, p := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+		}
+		*p = n
+	}
+	v.Pre = pre
+	return v, nil
+}
+
+// String returns the version in its canonical form.
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare returns -1, 0, or 1 when v is older than, the same as, or newer than o.
+// Pre-releases are older than the release itself, and are compared lexically with each other.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		} else if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.Pre == o.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case o.Pre == "":
+		return -1
+	}
+	return strings.Compare(v.Pre, o.Pre)
+}
+
+// AtLeast reports whether v is the same as or newer than o.
+func (v Version) AtLeast(o Version) bool {
+	return v.Compare(o) >= 0
+}
+
+// Feature is a capability of 1Password CLI not available in all supported versions.
+type Feature struct {
+	Name  string
+	Since Version
+}
+
+// FeatureISOTimestamps formats timestamps in ISO 8601 with the --iso-timestamps flag.
+var FeatureISOTimestamps = Feature{Name: "--iso-timestamps", Since: Version{Major: 2, Minor: 6}}
+
+// Detect reads the installed version of 1Password CLI and records it in Installed to gate features,
+// returning ErrUnsupportedVersion when it is older than MinVersion.
+func (c *CLI) Detect() error {
+	v, err := c.Version()
+	if err != nil {
+		return err
+	}
+	return c.Assume(v)
+}
+
+// Assume records v as the installed version like Detect, without running 1Password CLI.
+// It's meant for a version detected earlier, and returns ErrUnsupportedVersion when v is older than MinVersion.
+func (c *CLI) Assume(v Version) error {
+	c.Installed = &v
+	if !v.AtLeast(MinVersion) {
+		return fmt.Errorf("%w %s: version %s or later is required", ErrUnsupportedVersion, v, MinVersion)
+	}
+	return nil
+}
+
+// Supports reports whether the installed version supports the feature.
+// Without a detected version (see Detect), all features are assumed to be supported.
+func (c CLI) Supports(f Feature) bool {
+	return c.Installed == nil || c.Installed.AtLeast(f.Since)
+}
@@ -0,0 +1,137 @@
+package opcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		resp Version
+		err  string
+	}{
+		{
+			name: "Release",
+			in:   "2.24.0\n",
+			resp: Version{Major: 2, Minor: 24},
+		},
+		{
+			name: "PreRelease",
+			in:   "v2.25.0-beta.01",
+			resp: Version{Major: 2, Minor: 25, Pre: "beta.01"},
+		},
+		{
+			name: "Incomplete",
+			in:   "2.24",
+			err:  `invalid version "2.24": expected major.minor.patch`,
+		},
+		{
+			name: "NotNumeric",
+			in:   "2.x.0",
+			err:  `invalid version "2.x.0": expected major.minor.patch`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := ParseVersion(test.in)
+			if test.err == "" {
+				assert.Equal(t, test.resp, resp)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.24.0", "2.24.0", 0},
+		{"2.24.0", "2.3.0", 1},
+		{"1.12.4", "2.0.0", -1},
+		{"2.0.1", "2.0.0", 1},
+		{"2.25.0-beta.01", "2.25.0", -1},
+		{"2.25.0-beta.02", "2.25.0-beta.01", 1},
+	}
+	for _, test := range tests {
+		t.Run(test.a+"_"+test.b, func(t *testing.T) {
+			a, _ := ParseVersion(test.a)
+			b, _ := ParseVersion(test.b)
+			assert.Equal(t, test.want, a.Compare(b))
+			assert.Equal(t, -test.want, b.Compare(a))
+			assert.Equal(t, test.want >= 0, a.AtLeast(b))
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed *Version
+		err       string
+	}{
+		{
+			name:      "Success",
+			installed: &Version{Major: 2, Minor: 24},
+		},
+		{
+			name:      "Unsupported",
+			installed: &Version{Major: 1, Minor: 12, Patch: 4},
+			err:       "unsupported op version 1.12.4: version 2.0.0 or later is required",
+		},
+		{
+			name: "Invalid",
+			err:  `invalid version "op version 2\n": expected major.minor.patch`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t)}
+			err := cli.Detect()
+			assert.Equal(t, test.installed, cli.Installed)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestAssume(t *testing.T) {
+	cli := &CLI{}
+	assert.NoError(t, cli.Assume(Version{Major: 2, Minor: 24}))
+	assert.Equal(t, &Version{Major: 2, Minor: 24}, cli.Installed)
+	assert.EqualError(t, cli.Assume(Version{Major: 1, Minor: 12, Patch: 4}), "unsupported op version 1.12.4: version 2.0.0 or later is required")
+	assert.Equal(t, &Version{Major: 1, Minor: 12, Patch: 4}, cli.Installed)
+}
+
+func TestISOTimestamps(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed *Version
+	}{
+		{
+			name:      "Supported",
+			installed: &Version{Major: 2, Minor: 6},
+		},
+		{
+			name:      "Unsupported",
+			installed: &Version{Major: 2, Minor: 5, Patch: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cli := &CLI{Path: mockOp(t), Installed: test.installed}
+			resp, err := cli.GetVault("Personal")
+			assert.NoError(t, err)
+			assert.Equal(t, &Vault{ID: "ynghx4vwntpezvhqyeglcp7v7f", Name: "Personal"}, resp)
+		})
+	}
+}
@@ -2,9 +2,29 @@
 
 cd "${0%/*}" || exit
 
-cmd=$(awk 'NR==1' < op_response)
-status=$(awk 'NR==2' < op_response)
-response=$(awk 'NR>=3' < op_response)
+echo "$*" >> op_calls
+cat >> op_stdin
+
+# A test may provide several responses in the op_responses directory;
+# the one whose command matches the invocation is used.
+response_file=op_response
+if [ -d op_responses ]; then
+    for f in op_responses/*; do
+        if [ "$(awk 'NR==1' < "$f")" = "$*" ]; then
+            response_file=$f
+            break
+        fi
+    done
+fi
+
+if [ ! -f "$response_file" ]; then
+    echo "unexpected cmd: $*" >&2
+    exit 42
+fi
+
+cmd=$(awk 'NR==1' < "$response_file")
+status=$(awk 'NR==2' < "$response_file")
+response=$(awk 'NR>=3' < "$response_file")
 
 
 if [ "$cmd" != "$*" ]; then